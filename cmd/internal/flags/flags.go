@@ -0,0 +1,23 @@
+// Package flags holds the command line flags shared by the commands.
+package flags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TagFlags collects repeated `key=value` flags, such as `--tag nf.app=api`.
+type TagFlags map[string]string
+
+func (t TagFlags) String() string {
+	return fmt.Sprint(map[string]string(t))
+}
+
+func (t TagFlags) Set(value string) error {
+	k, v, ok := strings.Cut(value, "=")
+	if !ok || k == "" || v == "" {
+		return fmt.Errorf("tag must be in the form key=value: %s", value)
+	}
+	t[k] = v
+	return nil
+}
@@ -0,0 +1,28 @@
+package flags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTagFlags_Set(t *testing.T) {
+	tags := TagFlags{}
+	for _, value := range []string{"nf.app=api", "id=a=b"} {
+		if err := tags.Set(value); err != nil {
+			t.Errorf("Unexpected error for '%s': %v", value, err)
+		}
+	}
+
+	expected := TagFlags{"nf.app": "api", "id": "a=b"}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("Expected %v, got %v", expected, tags)
+	}
+}
+
+func TestTagFlags_SetInvalid(t *testing.T) {
+	for _, value := range []string{"nf.app", "=api", "nf.app="} {
+		if err := (TagFlags{}).Set(value); err == nil {
+			t.Errorf("Expected error for '%s'", value)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runWithFiles(t *testing.T, input string, args ...string) (int, string, string) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "recorded.log")
+	out := filepath.Join(dir, "replayed.log")
+	_ = os.WriteFile(in, []byte(input), 0644)
+	var stderr bytes.Buffer
+
+	args = append(append([]string{"--location", "file://" + out}, args...), in)
+	code := run(context.Background(), args, &stderr)
+
+	content, _ := os.ReadFile(out)
+	return code, strings.TrimRight(string(content), "\n"), stderr.String()
+}
+
+func TestRun_Flags(t *testing.T) {
+	input := "c:requests,nf.app=api,node=i-1:1\nnot a line\n"
+	code, output, stderr := runWithFiles(t, input, "--rate", "1000", "--tag", "nf.app=replay", "--tag", "env=test", "--drop-tag", "node")
+
+	if code != 0 {
+		t.Errorf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	expected := "c:requests,env=test,nf.app=replay:1"
+	if output != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, output)
+	}
+	if !strings.Contains(stderr, "replayed 1 lines, skipped 1 invalid lines") {
+		t.Errorf("Unexpected status '%s'", stderr)
+	}
+}
+
+func TestRun_InvalidArguments(t *testing.T) {
+	testCases := [][]string{
+		{"--tag", "nf.app"},
+		{"--tag", "=replay"},
+		{"--rate", "fast"},
+		{"--time-scale", "2"},
+		{"--location", "invalid"},
+	}
+
+	for _, args := range testCases {
+		code, output, _ := runWithFiles(t, "c:a:1\n", args...)
+		if code != 2 {
+			t.Errorf("%v: expected exit code 2, got %d", args, code)
+		}
+		if output != "" {
+			t.Errorf("%v: expected no output, got '%s'", args, output)
+		}
+	}
+
+	var stderr bytes.Buffer
+	if code := run(context.Background(), nil, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 without files, got %d", code)
+	}
+}
+
+func TestRun_NegativeRate(t *testing.T) {
+	code, output, stderr := runWithFiles(t, "c:a:1\n", "--rate", "-1")
+	if code != 1 || !strings.Contains(stderr, "cannot be negative") {
+		t.Errorf("Expected the library to reject the rate, got exit code %d: %s", code, stderr)
+	}
+	if output != "" {
+		t.Errorf("Expected no output, got '%s'", output)
+	}
+}
@@ -0,0 +1,144 @@
+// Command spectator-tap decodes spectatord protocol traffic, to help debug missing metrics. It binds a
+// local UDP or unixgram address, or reads a file written by the FileWriter, and prints the meter type,
+// name, sorted tags and value of every line, followed by a per-series summary table.
+//
+// Usage:
+//
+//	spectator-tap [flags]
+//
+// For example, to watch the timers with a name starting with `server.` and the tag `status=500`,
+// with per-series rates every 10 seconds:
+//
+//	spectator-tap --listen udp://127.0.0.1:1234 --name 'server.*' --tag status=500 --interval 10s
+//
+// Only one process may bind an address, so stop spectatord, or point the application at a different
+// address, before tapping its traffic.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/Netflix/spectator-go/v2/cmd/internal/flags"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// maxDatagramSize is large enough for any payload written by the spectator-go buffers.
+const maxDatagramSize = 64 * 1024
+
+func main() {
+	tags := flags.TagFlags{}
+
+	listen := flag.String("listen", "udp://127.0.0.1:1234", "source of protocol lines: udp://host:port, unix:///path, or file:///path")
+	name := flag.String("name", "", "only show meters with a name matching this glob pattern")
+	interval := flag.Duration("interval", 0, "print per-series rates on this interval, 0 to disable")
+	quiet := flag.Bool("quiet", false, "do not print individual lines, only the rates and the summary")
+	flag.Var(tags, "tag", "only show meters with this tag, as key=value; a value of * matches any value (repeatable)")
+	flag.Parse()
+
+	f := &filter{namePattern: *name, tags: tags}
+	if err := f.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "spectator-tap: %v\n", err)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	t := newTap(os.Stdout, f, *quiet)
+	if err := t.run(ctx, *listen, *interval); err != nil {
+		fmt.Fprintf(os.Stderr, "spectator-tap: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run reads from the source until it is exhausted, or the context is cancelled, and prints the summary.
+func (t *tap) run(ctx context.Context, source string, interval time.Duration) error {
+	payloads := make(chan string, 1024)
+	errCh := make(chan error, 1)
+
+	switch {
+	case strings.HasPrefix(source, "file://"):
+		file, err := os.Open(strings.TrimPrefix(source, "file://"))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		go readLines(file, payloads, errCh)
+	case strings.HasPrefix(source, "udp://"):
+		conn, err := net.ListenPacket("udp", strings.TrimPrefix(source, "udp://"))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		go readDatagrams(conn, payloads, errCh)
+	case strings.HasPrefix(source, "unix://"):
+		addr := &net.UnixAddr{Name: strings.TrimPrefix(source, "unix://"), Net: "unixgram"}
+		conn, err := net.ListenUnixgram("unixgram", addr)
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(addr.Name, "@") {
+			// abstract sockets have no file to remove
+			defer os.Remove(addr.Name)
+		}
+		defer conn.Close()
+		go readDatagrams(conn, payloads, errCh)
+	default:
+		return fmt.Errorf("unsupported source: %s", source)
+	}
+
+	var ticks <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	for {
+		select {
+		case payload := <-payloads:
+			t.handle(payload, time.Now())
+		case now := <-ticks:
+			t.printRates(now)
+		case err := <-errCh:
+			// drain any payloads read before the source was exhausted
+			for len(payloads) > 0 {
+				t.handle(<-payloads, time.Now())
+			}
+			t.printSummary(time.Now())
+			return err
+		case <-ctx.Done():
+			t.printSummary(time.Now())
+			return nil
+		}
+	}
+}
+
+// readLines sends each line of the reader, and then reports nil at the end of input.
+func readLines(r io.Reader, payloads chan<- string, errCh chan<- error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		payloads <- scanner.Text()
+	}
+	errCh <- scanner.Err()
+}
+
+// readDatagrams sends each datagram received on the connection, until it is closed.
+func readDatagrams(conn net.PacketConn, payloads chan<- string, errCh chan<- error) {
+	buffer := make([]byte, maxDatagramSize)
+	for {
+		n, _, err := conn.ReadFrom(buffer)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		payloads <- string(buffer[:n])
+	}
+}
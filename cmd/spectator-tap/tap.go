@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Netflix/spectator-go/v2/spectator"
+	"github.com/Netflix/spectator-go/v2/spectator/meter"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// formatId prints the name and sorted tags of the meter, e.g. `server.requests{method=GET, status=200}`.
+func formatId(id *meter.Id) string {
+	var sb strings.Builder
+	sb.WriteString(id.Name())
+	sb.WriteString("{")
+	for i, t := range id.TagList() {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(t.Key)
+		sb.WriteString("=")
+		sb.WriteString(t.Value)
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// filter selects meters by name glob and tags. A tag value of `*` matches any value.
+type filter struct {
+	namePattern string
+	tags        map[string]string
+}
+
+func (f *filter) validate() error {
+	if f.namePattern == "" {
+		return nil
+	}
+	_, err := path.Match(f.namePattern, "")
+	return err
+}
+
+func (f *filter) matches(id *meter.Id) bool {
+	if f.namePattern != "" {
+		if ok, _ := path.Match(f.namePattern, id.Name()); !ok {
+			return false
+		}
+	}
+	for k, v := range f.tags {
+		actual, ok := id.Tag(k)
+		if !ok || (v != "*" && v != actual) {
+			return false
+		}
+	}
+	return true
+}
+
+// series tracks the activity of a single meter type and Id.
+type series struct {
+	meterType   string
+	id          string
+	count       int
+	windowCount int
+	lastValue   string
+	lastSeen    time.Time
+}
+
+// tap decodes payloads into protocol lines, prints the matching lines, and tracks per-series activity.
+type tap struct {
+	out         io.Writer
+	filter      *filter
+	quiet       bool
+	series      map[string]*series
+	invalid     int
+	start       time.Time
+	windowStart time.Time
+}
+
+func newTap(out io.Writer, f *filter, quiet bool) *tap {
+	now := time.Now()
+	return &tap{
+		out:         out,
+		filter:      f,
+		quiet:       quiet,
+		series:      map[string]*series{},
+		start:       now,
+		windowStart: now,
+	}
+}
+
+// handle decodes a payload, which may hold several lines joined by newlines, as written by the buffers.
+func (t *tap) handle(payload string, now time.Time) {
+	for _, line := range strings.Split(payload, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		symbol, id, value, err := spectator.ParseProtocolLine(line)
+		if err != nil {
+			t.invalid++
+			if !t.quiet {
+				fmt.Fprintf(t.out, "%s invalid line %q: %v\n", now.Format(time.TimeOnly), line, err)
+			}
+			continue
+		}
+
+		if !t.filter.matches(id) {
+			continue
+		}
+
+		typ := spectator.MeterType(symbol)
+		formatted := formatId(id)
+		if !t.quiet {
+			fmt.Fprintf(t.out, "%s %-24s %s %s\n", now.Format(time.TimeOnly), typ, formatted, value)
+		}
+
+		key := typ + " " + formatted
+		s, ok := t.series[key]
+		if !ok {
+			s = &series{meterType: typ, id: formatted}
+			t.series[key] = s
+		}
+		s.count++
+		s.windowCount++
+		s.lastValue = value
+		s.lastSeen = now
+	}
+}
+
+// sortedSeries returns the series ordered by Id, and then by meter type.
+func (t *tap) sortedSeries() []*series {
+	all := make([]*series, 0, len(t.series))
+	for _, s := range t.series {
+		all = append(all, s)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].id != all[j].id {
+			return all[i].id < all[j].id
+		}
+		return all[i].meterType < all[j].meterType
+	})
+	return all
+}
+
+// printRates prints the per-series rate of lines since the previous call, and starts a new window.
+func (t *tap) printRates(now time.Time) {
+	elapsed := now.Sub(t.windowStart).Seconds()
+	t.windowStart = now
+
+	tw := tabwriter.NewWriter(t.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "--- rates at %s\n", now.Format(time.TimeOnly))
+	fmt.Fprintln(tw, "TYPE\tID\tLINES/SEC\tLAST VALUE")
+	for _, s := range t.sortedSeries() {
+		if s.windowCount == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.2f\t%s\n", s.meterType, s.id, rate(s.windowCount, elapsed), s.lastValue)
+		s.windowCount = 0
+	}
+	tw.Flush()
+}
+
+// printSummary prints the activity of every series seen since the tap started.
+func (t *tap) printSummary(now time.Time) {
+	elapsed := now.Sub(t.start).Seconds()
+
+	tw := tabwriter.NewWriter(t.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "--- summary of %d series over %.1f seconds, %d invalid lines\n", len(t.series), elapsed, t.invalid)
+	fmt.Fprintln(tw, "TYPE\tID\tLINES\tLINES/SEC\tLAST VALUE\tLAST SEEN")
+	for _, s := range t.sortedSeries() {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.2f\t%s\t%s\n", s.meterType, s.id, s.count, rate(s.count, elapsed), s.lastValue, s.lastSeen.Format(time.TimeOnly))
+	}
+	tw.Flush()
+}
+
+func rate(count int, seconds float64) float64 {
+	if seconds <= 0 {
+		return 0
+	}
+	return float64(count) / seconds
+}
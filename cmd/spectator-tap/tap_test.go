@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/Netflix/spectator-go/v2/spectator/meter"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatId_SortsTags(t *testing.T) {
+	id := meter.NewId("server.requests", map[string]string{"status": "200", "method": "GET"})
+
+	expected := "server.requests{method=GET, status=200}"
+	if actual := formatId(id); actual != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, actual)
+	}
+}
+
+func TestFilter_Matches(t *testing.T) {
+	id := meter.NewId("server.requests", map[string]string{"status": "200"})
+
+	testCases := []struct {
+		filter   filter
+		expected bool
+	}{
+		{filter{}, true},
+		{filter{namePattern: "server.*"}, true},
+		{filter{namePattern: "client.*"}, false},
+		{filter{tags: map[string]string{"status": "200"}}, true},
+		{filter{tags: map[string]string{"status": "500"}}, false},
+		{filter{tags: map[string]string{"status": "*"}}, true},
+		{filter{tags: map[string]string{"method": "*"}}, false},
+	}
+
+	for _, tc := range testCases {
+		if actual := tc.filter.matches(id); actual != tc.expected {
+			t.Errorf("Expected %v for filter %+v, got %v", tc.expected, tc.filter, actual)
+		}
+	}
+}
+
+func TestFilter_InvalidPattern(t *testing.T) {
+	f := &filter{namePattern: "["}
+	if f.validate() == nil {
+		t.Errorf("Expected error for invalid pattern")
+	}
+}
+
+func TestTap_HandleBufferedPayload(t *testing.T) {
+	var out bytes.Buffer
+	tp := newTap(&out, &filter{namePattern: "server.*"}, false)
+
+	tp.handle("c:server.requests,status=200:1\nc:server.requests,status=200:1\ng:other:1\ninvalid", time.Now())
+
+	if len(tp.series) != 1 {
+		t.Fatalf("Expected 1 series, got %d", len(tp.series))
+	}
+	for _, s := range tp.series {
+		if s.count != 2 || s.lastValue != "1" {
+			t.Errorf("Expected 2 lines with last value 1, got %+v", s)
+		}
+	}
+	if tp.invalid != 1 {
+		t.Errorf("Expected 1 invalid line, got %d", tp.invalid)
+	}
+	if !strings.Contains(out.String(), "counter                  server.requests{status=200} 1") {
+		t.Errorf("Unexpected output '%s'", out.String())
+	}
+}
+
+func TestTap_PrintRatesResetsWindow(t *testing.T) {
+	var out bytes.Buffer
+	tp := newTap(&out, &filter{}, true)
+	start := tp.windowStart
+
+	tp.handle("c:counter:1\nc:counter:1", start)
+	tp.printRates(start.Add(time.Second))
+
+	if !strings.Contains(out.String(), "2.00") {
+		t.Errorf("Expected rate of 2.00 lines/sec in '%s'", out.String())
+	}
+	for _, s := range tp.series {
+		if s.windowCount != 0 {
+			t.Errorf("Expected window to be reset, got %d", s.windowCount)
+		}
+	}
+}
+
+func TestTap_RunFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.log")
+	_ = os.WriteFile(path, []byte("c:a:1\nt:b,k=v:0.5\n"), 0644)
+
+	var out bytes.Buffer
+	tp := newTap(&out, &filter{}, true)
+	if err := tp.run(context.Background(), "file://"+path, 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output := out.String()
+	if !strings.Contains(output, "summary of 2 series") || !strings.Contains(output, "b{k=v}") {
+		t.Errorf("Unexpected summary '%s'", output)
+	}
+}
+
+func TestTap_RunUdp(t *testing.T) {
+	var out bytes.Buffer
+	tp := newTap(&out, &filter{}, true)
+
+	// reserve a free port for the tap
+	probe, _ := net.ListenPacket("udp", "127.0.0.1:0")
+	addr := probe.LocalAddr().String()
+	_ = probe.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- tp.run(ctx, "udp://"+addr, 0) }()
+
+	time.Sleep(20 * time.Millisecond)
+	conn, _ := net.Dial("udp", addr)
+	_, _ = conn.Write([]byte("c:udp.counter:1"))
+	_ = conn.Close()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "udp.counter{}") {
+		t.Errorf("Unexpected summary '%s'", out.String())
+	}
+}
+
+func TestTap_RunAbstractUnixKeepsFile(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("abstract unix sockets are only supported on linux")
+	}
+
+	// a file with the name of the abstract socket, in the working directory, must not be removed
+	wd, _ := os.Getwd()
+	_ = os.Chdir(t.TempDir())
+	defer func() { _ = os.Chdir(wd) }()
+	name := fmt.Sprintf("@spectator-tap-test-%d", os.Getpid())
+	_ = os.WriteFile(name, nil, 0644)
+
+	var out bytes.Buffer
+	tp := newTap(&out, &filter{}, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- tp.run(ctx, "unix://"+name, 0) }()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(name); err != nil {
+		t.Errorf("Expected the file %s to be kept, got %v", name, err)
+	}
+}
@@ -0,0 +1,300 @@
+// Command spectator publishes metrics from shell scripts, cron jobs and deploy scripts, which cannot link
+// Go code. Each invocation builds one protocol line with the meter types of the spectator package, and
+// sends it to the output location.
+//
+// Usage:
+//
+//	spectator [--location LOCATION] COMMAND [flags] NAME [VALUE] [key=value...]
+//	spectator [--location LOCATION] time NAME [key=value...] -- COMMAND [ARG...]
+//
+// For example:
+//
+//	spectator counter deploy.count app=api
+//	spectator gauge --ttl 5m queue.depth 42 queue=jobs
+//	spectator timer backup.duration 93.5s
+//	spectator age-gauge backup.lastSuccess
+//	spectator time cron.duration job=cleanup -- ./cleanup.sh --all
+//
+// The time command runs the command, records its duration as a timer, tagged with `status` (`success` or
+// `failure`) and `exit.code`, and exits with the exit code of the command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/Netflix/spectator-go/v2/spectator"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const usage = `Usage: spectator [--location LOCATION] COMMAND [flags] NAME [VALUE] [key=value...]
+
+Commands:
+  counter                  NAME [DELTA]      increment a counter, by 1 or DELTA
+  monotonic-counter        NAME VALUE        set a monotonic counter
+  gauge [--ttl DURATION]   NAME VALUE        set a gauge
+  max-gauge                NAME VALUE        set a max gauge
+  age-gauge                NAME [SECONDS]    set an age gauge, to now or to SECONDS since the epoch
+  timer                    NAME DURATION     record a timer, as a Go duration (e.g. 250ms) or seconds
+  percentile-timer         NAME DURATION     record a percentile timer
+  dist-summary             NAME AMOUNT       record a distribution summary
+  percentile-dist-summary  NAME AMOUNT       record a percentile distribution summary
+  time NAME [key=value...] -- COMMAND [ARG...]
+                                             run COMMAND, and record its duration and exit status
+
+Flags:
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stderr))
+}
+
+// run executes the command line, and returns the process exit code.
+func run(args []string, stderr io.Writer) int {
+	flags := flag.NewFlagSet("spectator", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	location := flags.String("location", "udp", "output location: udp, unix, stdout, file:///path, udp://host:port, unix:///path")
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	config, err := spectator.NewConfig(*location, nil, &cliLogger{stderr})
+	if err != nil {
+		fmt.Fprintf(stderr, "spectator: %v\n", err)
+		return 2
+	}
+	registry, err := spectator.NewRegistry(config)
+	if err != nil {
+		fmt.Fprintf(stderr, "spectator: %v\n", err)
+		return 1
+	}
+	defer registry.Close()
+
+	command, commandArgs := flags.Arg(0), flags.Args()[1:]
+	if command == "time" {
+		return runTime(registry, commandArgs, stderr)
+	}
+
+	if err := record(registry, command, commandArgs); err != nil {
+		fmt.Fprintf(stderr, "spectator: %s: %v\n", command, err)
+		return 2
+	}
+	return 0
+}
+
+// record parses the arguments of a meter command, and records the value.
+func record(registry spectator.Registry, command string, args []string) error {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	ttl := flags.Duration("ttl", 0, "gauge time to live")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	name, value, tags, err := parseMeterArgs(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "counter":
+		if value == "" {
+			registry.Counter(name, tags).Increment()
+			return nil
+		}
+		delta, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		registry.Counter(name, tags).AddFloat(delta)
+	case "monotonic-counter":
+		v, err := parseFloat(value)
+		if err != nil {
+			return err
+		}
+		registry.MonotonicCounter(name, tags).Set(v)
+	case "gauge":
+		v, err := parseFloat(value)
+		if err != nil {
+			return err
+		}
+		if *ttl > 0 {
+			registry.GaugeWithTTL(name, tags, *ttl).Set(v)
+		} else {
+			registry.Gauge(name, tags).Set(v)
+		}
+	case "max-gauge":
+		v, err := parseFloat(value)
+		if err != nil {
+			return err
+		}
+		registry.MaxGauge(name, tags).Set(v)
+	case "age-gauge":
+		if value == "" {
+			registry.AgeGauge(name, tags).Now()
+			return nil
+		}
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		registry.AgeGauge(name, tags).Set(seconds)
+	case "timer", "percentile-timer":
+		d, err := parseDuration(value)
+		if err != nil {
+			return err
+		}
+		if command == "timer" {
+			registry.Timer(name, tags).Record(d)
+		} else {
+			registry.PercentileTimer(name, tags).Record(d)
+		}
+	case "dist-summary", "percentile-dist-summary":
+		amount, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		if command == "dist-summary" {
+			registry.DistributionSummary(name, tags).Record(amount)
+		} else {
+			registry.PercentileDistributionSummary(name, tags).Record(amount)
+		}
+	default:
+		return fmt.Errorf("unknown command")
+	}
+
+	return nil
+}
+
+// runTime runs the command after the `--` separator, records its duration and exit status, and returns
+// the exit code of the command.
+func runTime(registry spectator.Registry, args []string, stderr io.Writer) int {
+	sep := -1
+	for i, arg := range args {
+		if arg == "--" {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 || sep == len(args)-1 {
+		fmt.Fprintln(stderr, "spectator: time: expected NAME [key=value...] -- COMMAND [ARG...]")
+		return 2
+	}
+
+	name, value, tags, err := parseMeterArgs(args[:sep])
+	if err != nil || value != "" {
+		fmt.Fprintln(stderr, "spectator: time: expected NAME [key=value...] -- COMMAND [ARG...]")
+		return 2
+	}
+	if tags == nil {
+		tags = map[string]string{}
+	}
+
+	cmd := exec.Command(args[sep+1], args[sep+2:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	err = cmd.Run()
+	duration := time.Since(start)
+
+	exitCode := 0
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		exitCode = exitErr.ExitCode()
+	default:
+		// the command could not be started, follow the shell convention
+		fmt.Fprintf(stderr, "spectator: time: %v\n", err)
+		exitCode = 127
+	}
+
+	tags["status"] = "success"
+	if exitCode != 0 {
+		tags["status"] = "failure"
+	}
+	tags["exit.code"] = strconv.Itoa(exitCode)
+	registry.Timer(name, tags).Record(duration)
+
+	return exitCode
+}
+
+// parseMeterArgs splits the positional arguments into the meter name, an optional value, and the
+// `key=value` tags, which may appear in any order after the name.
+func parseMeterArgs(args []string) (string, string, map[string]string, error) {
+	if len(args) == 0 || strings.Contains(args[0], "=") {
+		return "", "", nil, fmt.Errorf("missing meter name")
+	}
+
+	var value string
+	var tags map[string]string
+	for _, arg := range args[1:] {
+		k, v, ok := strings.Cut(arg, "=")
+		if !ok {
+			if value != "" {
+				return "", "", nil, fmt.Errorf("unexpected argument: %s", arg)
+			}
+			value = arg
+			continue
+		}
+		if k == "" || v == "" {
+			return "", "", nil, fmt.Errorf("tag must be in the form key=value: %s", arg)
+		}
+		if tags == nil {
+			tags = map[string]string{}
+		}
+		tags[k] = v
+	}
+
+	return args[0], value, tags, nil
+}
+
+func parseFloat(value string) (float64, error) {
+	if value == "" {
+		return 0, fmt.Errorf("missing value")
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// parseDuration accepts a Go duration, such as `250ms`, or a number of seconds.
+func parseDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, fmt.Errorf("missing duration")
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d, nil
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %s", value)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// cliLogger keeps the output of the command quiet, by only reporting errors.
+type cliLogger struct {
+	out io.Writer
+}
+
+func (l *cliLogger) Debugf(string, ...interface{}) {}
+
+func (l *cliLogger) Infof(string, ...interface{}) {}
+
+func (l *cliLogger) Errorf(format string, v ...interface{}) {
+	fmt.Fprintf(l.out, "spectator: "+format+"\n", v...)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runWithFile(t *testing.T, args ...string) (int, string, string) {
+	path := filepath.Join(t.TempDir(), "metrics.log")
+	var stderr bytes.Buffer
+
+	code := run(append([]string{"--location", "file://" + path}, args...), &stderr)
+
+	content, _ := os.ReadFile(path)
+	return code, strings.TrimRight(string(content), "\n"), stderr.String()
+}
+
+func TestRun_Meters(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"counter", "deploy.count"}, "c:deploy.count:1"},
+		{[]string{"counter", "bytes", "2.5"}, "c:bytes:2.500000"},
+		{[]string{"gauge", "--ttl", "5m", "queue.depth", "42", "queue=jobs"}, "g,300:queue.depth,queue=jobs:42.000000"},
+		{[]string{"gauge", "queue.depth", "42"}, "g:queue.depth:42.000000"},
+		{[]string{"max-gauge", "max", "7"}, "m:max:7.000000"},
+		{[]string{"age-gauge", "backup.lastSuccess"}, "A:backup.lastSuccess:0"},
+		{[]string{"age-gauge", "backup.lastSuccess", "1700000000"}, "A:backup.lastSuccess:1700000000"},
+		{[]string{"timer", "backup.duration", "250ms"}, "t:backup.duration:0.250000"},
+		{[]string{"timer", "backup.duration", "1.5"}, "t:backup.duration:1.500000"},
+		{[]string{"percentile-timer", "latency", "2s"}, "T:latency:2.000000"},
+		{[]string{"dist-summary", "size", "100"}, "d:size:100"},
+		{[]string{"percentile-dist-summary", "size", "100"}, "D:size:100"},
+		{[]string{"monotonic-counter", "total", "10"}, "C:total:10.000000"},
+	}
+
+	for _, tc := range testCases {
+		code, output, stderr := runWithFile(t, tc.args...)
+		if code != 0 {
+			t.Errorf("%v: expected exit code 0, got %d: %s", tc.args, code, stderr)
+		}
+		if output != tc.expected {
+			t.Errorf("%v: expected '%s', got '%s'", tc.args, tc.expected, output)
+		}
+	}
+}
+
+func TestRun_InvalidArguments(t *testing.T) {
+	testCases := [][]string{
+		{},
+		{"unknown", "name"},
+		{"counter"},
+		{"counter", "a=b"},
+		{"gauge", "name"},
+		{"timer", "name", "soon"},
+		{"counter", "name", "1", "2"},
+		{"counter", "name", "=b"},
+	}
+
+	for _, args := range testCases {
+		code, output, _ := runWithFile(t, args...)
+		if code != 2 {
+			t.Errorf("%v: expected exit code 2, got %d", args, code)
+		}
+		if output != "" {
+			t.Errorf("%v: expected no output, got '%s'", args, output)
+		}
+	}
+}
+
+func TestRun_Time(t *testing.T) {
+	code, output, _ := runWithFile(t, "time", "cron.duration", "job=cleanup", "--", "sh", "-c", "exit 3")
+	if code != 3 {
+		t.Errorf("Expected exit code 3, got %d", code)
+	}
+
+	for _, expected := range []string{"t:cron.duration,", "job=cleanup", "status=failure", "exit.code=3"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, output)
+		}
+	}
+}
+
+func TestRun_TimeSuccess(t *testing.T) {
+	code, output, _ := runWithFile(t, "time", "cron.duration", "--", "true")
+	if code != 0 {
+		t.Errorf("Expected exit code 0, got %d", code)
+	}
+	if !strings.Contains(output, "status=success") || !strings.Contains(output, "exit.code=0") {
+		t.Errorf("Expected success tags in '%s'", output)
+	}
+}
+
+func TestRun_TimeMissingCommand(t *testing.T) {
+	code, _, _ := runWithFile(t, "time", "cron.duration", "--")
+	if code != 2 {
+		t.Errorf("Expected exit code 2, got %d", code)
+	}
+}
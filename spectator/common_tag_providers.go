@@ -0,0 +1,327 @@
+package spectator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// CommonTagProvider discovers common tags for the process, such as the pod or the instance it runs on. The
+// providers are called once, when the Config is created, and their tags are added to every metric.
+type CommonTagProvider interface {
+	// Name identifies the provider in log messages.
+	Name() string
+	// Tags returns the discovered tags. Tags with an empty key or value are ignored. If an error is
+	// returned, it is logged, and the tags of the provider are not used.
+	Tags() (map[string]string, error)
+}
+
+// CommonTagProviderFunc adapts a function to the CommonTagProvider interface.
+type CommonTagProviderFunc struct {
+	ProviderName string
+	Fn           func() (map[string]string, error)
+}
+
+func (p CommonTagProviderFunc) Name() string {
+	return p.ProviderName
+}
+
+func (p CommonTagProviderFunc) Tags() (map[string]string, error) {
+	return p.Fn()
+}
+
+// metadataTimeout bounds each request made to a metadata endpoint, so that a missing endpoint does not
+// delay the creation of the Config for long.
+const metadataTimeout = 2 * time.Second
+
+// fileTagProvider reads one tag per file, skipping the files that do not exist.
+type fileTagProvider struct {
+	name  string
+	files map[string]string // tag key -> file path
+}
+
+// NewKubernetesProvider reads the pod name, namespace and node name from Kubernetes downward API files,
+// in the directory where the volume is mounted:
+//
+//   - `pod_name`      - Added as the `k8s.pod` tag.
+//   - `pod_namespace` - Added as the `k8s.namespace` tag.
+//   - `node_name`     - Added as the `k8s.node` tag.
+//
+// The volume items should map these paths to the `metadata.name`, `metadata.namespace` and
+// `spec.nodeName` fields. Missing files are skipped.
+func NewKubernetesProvider(dir string) CommonTagProvider {
+	return &fileTagProvider{
+		name: "kubernetes",
+		files: map[string]string{
+			"k8s.pod":       filepath.Join(dir, "pod_name"),
+			"k8s.namespace": filepath.Join(dir, "pod_namespace"),
+			"k8s.node":      filepath.Join(dir, "node_name"),
+		},
+	}
+}
+
+func (p *fileTagProvider) Name() string {
+	return p.name
+}
+
+func (p *fileTagProvider) Tags() (map[string]string, error) {
+	tags := make(map[string]string)
+	for tag, path := range p.files {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tags[tag] = strings.TrimSpace(string(data))
+	}
+	return tags, nil
+}
+
+// staticFileProvider reads `key=value` lines from a file.
+type staticFileProvider struct {
+	path string
+}
+
+// NewStaticFileProvider reads tags from a file with one `key=value` pair per line. Blank lines and lines
+// starting with `#` are ignored. Unlike the other providers, a missing file is reported as an error.
+func NewStaticFileProvider(path string) CommonTagProvider {
+	return &staticFileProvider{path: path}
+}
+
+func (p *staticFileProvider) Name() string {
+	return "file:" + p.path
+}
+
+func (p *staticFileProvider) Tags() (map[string]string, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key=value", lineNum)
+		}
+		tags[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return tags, scanner.Err()
+}
+
+// buildInfoProvider reads the version control information embedded by the Go toolchain.
+type buildInfoProvider struct {
+	readBuildInfo func() (*debug.BuildInfo, bool)
+}
+
+// NewBuildInfoProvider adds the build information embedded in the binary by `go build`:
+//
+//   - `build.version`  - The version of the main module, unless it is `(devel)`.
+//   - `build.commit`   - The VCS revision, with a `-dirty` suffix if there were local modifications.
+//
+// VCS information is only embedded when building from a repository, see the `-buildvcs` flag.
+func NewBuildInfoProvider() CommonTagProvider {
+	return &buildInfoProvider{readBuildInfo: debug.ReadBuildInfo}
+}
+
+func (p *buildInfoProvider) Name() string {
+	return "buildinfo"
+}
+
+func (p *buildInfoProvider) Tags() (map[string]string, error) {
+	info, ok := p.readBuildInfo()
+	if !ok {
+		return nil, errors.New("build information is not available")
+	}
+
+	tags := make(map[string]string)
+	if version := info.Main.Version; version != "" && version != "(devel)" {
+		tags["build.version"] = version
+	}
+
+	var revision string
+	var modified bool
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision != "" {
+		if modified {
+			revision += "-dirty"
+		}
+		tags["build.commit"] = revision
+	}
+	return tags, nil
+}
+
+// ec2Provider queries the EC2 instance metadata service.
+type ec2Provider struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewEC2Provider queries the EC2 instance metadata service at the endpoint, which defaults to
+// `http://169.254.169.254` when empty, and adds the tags:
+//
+//   - `nf.node`   - The instance id.
+//   - `nf.zone`   - The availability zone.
+//   - `nf.region` - The region.
+//   - `nf.vmtype` - The instance type.
+//
+// An IMDSv2 session token is requested first, and IMDSv1 is used if the token request fails.
+func NewEC2Provider(endpoint string) CommonTagProvider {
+	if endpoint == "" {
+		endpoint = "http://169.254.169.254"
+	}
+	return &ec2Provider{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Timeout: metadataTimeout},
+	}
+}
+
+func (p *ec2Provider) Name() string {
+	return "ec2"
+}
+
+func (p *ec2Provider) Tags() (map[string]string, error) {
+	token := p.token()
+
+	paths := map[string]string{
+		"nf.node":   "instance-id",
+		"nf.zone":   "placement/availability-zone",
+		"nf.region": "placement/region",
+		"nf.vmtype": "instance-type",
+	}
+
+	tags := make(map[string]string, len(paths))
+	for tag, path := range paths {
+		req, err := http.NewRequest(http.MethodGet, p.endpoint+"/latest/meta-data/"+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("X-aws-ec2-metadata-token", token)
+		}
+		value, err := fetch(p.client, req)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		tags[tag] = value
+	}
+	return tags, nil
+}
+
+// token requests an IMDSv2 session token, and returns an empty string if it is not available.
+func (p *ec2Provider) token() string {
+	req, err := http.NewRequest(http.MethodPut, p.endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	token, err := fetch(p.client, req)
+	if err != nil {
+		return ""
+	}
+	return token
+}
+
+// ecsProvider queries the ECS task metadata endpoint.
+type ecsProvider struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewECSProvider queries the ECS task metadata endpoint, which defaults to the value of the
+// ECS_CONTAINER_METADATA_URI_V4 environment variable when empty, and adds the tags:
+//
+//   - `ecs.cluster` - The cluster of the task.
+//   - `ecs.task`    - The task definition family and revision, such as `api:42`.
+//   - `nf.zone`     - The availability zone.
+//
+// When no endpoint is configured, the process is not running on ECS, and no tags are added.
+func NewECSProvider(endpoint string) CommonTagProvider {
+	return &ecsProvider{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Timeout: metadataTimeout},
+	}
+}
+
+func (p *ecsProvider) Name() string {
+	return "ecs"
+}
+
+func (p *ecsProvider) Tags() (map[string]string, error) {
+	endpoint := p.endpoint
+	if endpoint == "" {
+		endpoint = strings.TrimSuffix(os.Getenv("ECS_CONTAINER_METADATA_URI_V4"), "/")
+	}
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint+"/task", nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := fetch(p.client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var task struct {
+		Cluster          string `json:"Cluster"`
+		Family           string `json:"Family"`
+		Revision         string `json:"Revision"`
+		AvailabilityZone string `json:"AvailabilityZone"`
+	}
+	if err := json.Unmarshal([]byte(body), &task); err != nil {
+		return nil, fmt.Errorf("invalid task metadata: %w", err)
+	}
+
+	tags := map[string]string{
+		"ecs.cluster": task.Cluster,
+		"nf.zone":     task.AvailabilityZone,
+	}
+	if task.Family != "" && task.Revision != "" {
+		tags["ecs.task"] = task.Family + ":" + task.Revision
+	}
+	return tags, nil
+}
+
+// fetch performs the request, and returns the trimmed body of a successful response.
+func fetch(client *http.Client, req *http.Request) (string, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
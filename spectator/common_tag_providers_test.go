@@ -0,0 +1,197 @@
+package spectator
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestKubernetesProvider(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "pod_name"), []byte("api-7d9f\n"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "pod_namespace"), []byte("prod"), 0644)
+
+	tags, err := NewKubernetesProvider(dir).Tags()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{"k8s.pod": "api-7d9f", "k8s.namespace": "prod"}
+	if !reflect.DeepEqual(expected, tags) {
+		t.Errorf("Expected %v, got %v", expected, tags)
+	}
+}
+
+func TestStaticFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tags")
+	_ = os.WriteFile(path, []byte("# common tags\nnf.app = api\n\nnf.stack=prod\n"), 0644)
+
+	tags, err := NewStaticFileProvider(path).Tags()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{"nf.app": "api", "nf.stack": "prod"}
+	if !reflect.DeepEqual(expected, tags) {
+		t.Errorf("Expected %v, got %v", expected, tags)
+	}
+
+	_ = os.WriteFile(path, []byte("nf.app\n"), 0644)
+	if _, err := NewStaticFileProvider(path).Tags(); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected error for line 1, got %v", err)
+	}
+
+	if _, err := NewStaticFileProvider(filepath.Join(t.TempDir(), "missing")).Tags(); err == nil {
+		t.Errorf("Expected error for missing file, got nil")
+	}
+}
+
+func TestBuildInfoProvider(t *testing.T) {
+	provider := &buildInfoProvider{readBuildInfo: func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			Main: debug.Module{Version: "v1.2.3"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "abc123"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		}, true
+	}}
+
+	tags, err := provider.Tags()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{"build.version": "v1.2.3", "build.commit": "abc123-dirty"}
+	if !reflect.DeepEqual(expected, tags) {
+		t.Errorf("Expected %v, got %v", expected, tags)
+	}
+
+	provider.readBuildInfo = func() (*debug.BuildInfo, bool) { return nil, false }
+	if _, err := provider.Tags(); err == nil {
+		t.Errorf("Expected error when build info is not available, got nil")
+	}
+}
+
+func TestEC2Provider(t *testing.T) {
+	metadata := map[string]string{
+		"/latest/meta-data/instance-id":                 "i-0123456789",
+		"/latest/meta-data/placement/availability-zone": "us-east-1a",
+		"/latest/meta-data/placement/region":            "us-east-1",
+		"/latest/meta-data/instance-type":               "m5.large",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.URL.Path == "/latest/api/token" {
+			_, _ = w.Write([]byte("token"))
+			return
+		}
+		if r.Header.Get("X-aws-ec2-metadata-token") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		value, ok := metadata[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(value))
+	}))
+	defer server.Close()
+
+	tags, err := NewEC2Provider(server.URL).Tags()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"nf.node":   "i-0123456789",
+		"nf.zone":   "us-east-1a",
+		"nf.region": "us-east-1",
+		"nf.vmtype": "m5.large",
+	}
+	if !reflect.DeepEqual(expected, tags) {
+		t.Errorf("Expected %v, got %v", expected, tags)
+	}
+}
+
+func TestEC2Provider_Unavailable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	if _, err := NewEC2Provider(server.URL).Tags(); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestECSProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/task" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"Cluster": "prod", "Family": "api", "Revision": "42", "AvailabilityZone": "us-east-1b"}`))
+	}))
+	defer server.Close()
+
+	_ = os.Setenv("ECS_CONTAINER_METADATA_URI_V4", server.URL)
+	defer os.Unsetenv("ECS_CONTAINER_METADATA_URI_V4")
+
+	tags, err := NewECSProvider("").Tags()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{"ecs.cluster": "prod", "ecs.task": "api:42", "nf.zone": "us-east-1b"}
+	if !reflect.DeepEqual(expected, tags) {
+		t.Errorf("Expected %v, got %v", expected, tags)
+	}
+}
+
+func TestECSProvider_NotOnECS(t *testing.T) {
+	tags, err := NewECSProvider("").Tags()
+	if err != nil || len(tags) != 0 {
+		t.Errorf("Expected no tags and no error, got %v, %v", tags, err)
+	}
+}
+
+func TestCommonTagProviders_Precedence(t *testing.T) {
+	_ = os.Setenv("TITUS_CONTAINER_NAME", "env")
+	defer os.Unsetenv("TITUS_CONTAINER_NAME")
+
+	first := CommonTagProviderFunc{ProviderName: "first", Fn: func() (map[string]string, error) {
+		return map[string]string{"a": "first", "b": "first", "c": "first", "nf.container": "first", "empty": ""}, nil
+	}}
+	second := CommonTagProviderFunc{ProviderName: "second", Fn: func() (map[string]string, error) {
+		return map[string]string{"b": "second", "c": "second"}, nil
+	}}
+	failing := CommonTagProviderFunc{ProviderName: "failing", Fn: func() (map[string]string, error) {
+		return map[string]string{"a": "failing"}, errors.New("unavailable")
+	}}
+	log := &captureLogger{}
+
+	config, err := NewConfigWithOptions(
+		WithLogger(log),
+		WithCommonTagProviders(first, second),
+		WithCommonTagProviders(failing),
+		WithCommonTags(map[string]string{"c": "extra"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]string{"a": "first", "b": "second", "c": "extra", "nf.container": "env"}
+	if !reflect.DeepEqual(expected, config.CommonTags()) {
+		t.Errorf("Expected %v, got %v", expected, config.CommonTags())
+	}
+
+	errs := log.Errors()
+	if len(errs) != 1 || !strings.Contains(errs[0], "failing") {
+		t.Errorf("Expected one error for the failing provider, got %v", errs)
+	}
+}
@@ -15,6 +15,12 @@ type Config struct {
 	log             logger.Logger
 	bufferSize      int
 	flushInterval   time.Duration
+	validation      bool
+	meterFilters    []MeterFilter
+	sampleRules     []SampleRule
+	// writerLog is the logger of the writers, which rate limits the repeated messages.
+	writerLog         logger.Logger
+	writerLogInterval time.Duration
 }
 
 // NewConfig creates a new configuration with the provided location, extra common tags, and logger. All fields are
@@ -29,9 +35,14 @@ type Config struct {
 //   - `stdout` - Write metrics to standard output.
 //   - `udp`    - Write metrics to the default spectatord UDP port. This is the default value.
 //   - `unix`   - Write metrics to the default spectatord Unix Domain Socket. Useful for high-volume scenarios.
-//   - `file:///path/to/file`   - Write metrics to a file.
+//   - `file:///path/to/file`   - Write metrics to a file. Rotation, retention, compression and buffering may be
+//     configured with query parameters, such as `file:///path/to/file?maxSize=100MB&maxFiles=5&compress=true`.
+//     See writer.ParseFileLocation for the full list.
 //   - `udp://host:port`        - Write metrics to a UDP socket.
 //   - `unix:///path/to/socket` - Write metrics to a Unix Domain Socket.
+//   - `unix://@name`           - Write metrics to a Linux abstract Unix Domain Socket, such as `unix://@spectatord`.
+//   - `multi:udp,file:///path/to/file` - Write metrics to each of the comma separated locations, which may
+//     be any of the values above. Useful to send metrics to a second output during a migration.
 //
 // The output location can be overridden by configuring an environment variable SPECTATOR_OUTPUT_LOCATION
 // with one of the values listed above. Overriding the output location may be useful for integration testing.
@@ -40,7 +51,7 @@ func NewConfig(
 	extraCommonTags map[string]string, // defaults to empty map
 	log logger.Logger, // defaults to default logger
 ) (*Config, error) {
-	return NewConfigWithBuffer(location, extraCommonTags, log, 0, 5 * time.Second)
+	return NewConfigWithBuffer(location, extraCommonTags, log, 0, 5*time.Second)
 }
 
 // NewConfigWithBuffer creates a new configuration with the provided location, extra common tags, logger,
@@ -59,7 +70,8 @@ func NewConfig(
 //     lines/sec, with delays from 0.6 to 7 us, depending on thread count. The true minimum size is 2 * CPU *
 //     60KB, or 122,880 bytes for 1 CPU. Metrics may be dropped. Status metrics are published to monitor usage.
 //
-// The buffers are available for the UdpWriter and the UnixWriter.
+// The buffers are available for the UdpWriter and the UnixWriter. The FileWriter ignores the bufferSize, and its
+// buffered writes are enabled by the `bufferSize` query parameter of the location. See writer.ParseFileLocation.
 //
 // See https://netflix.github.io/atlas-docs/spectator/lang/go/usage/#buffers for a more detailed explanation.
 //
@@ -70,20 +82,70 @@ func NewConfigWithBuffer(
 	bufferSize int, // defaults to 0 (disabled)
 	flushInterval time.Duration, // defaults to 5 seconds
 ) (*Config, error) {
-	location, err := calculateLocation(location)
-	if err != nil {
-		return nil, err
+	return NewConfigWithOptions(
+		WithLocation(location),
+		WithCommonTags(extraCommonTags),
+		WithLogger(log),
+		WithBuffer(bufferSize),
+		WithFlushInterval(flushInterval),
+	)
+}
+
+// Location returns the resolved output location, after applying the SPECTATOR_OUTPUT_LOCATION override
+// and the default.
+func (c *Config) Location() string {
+	return c.location
+}
+
+// CommonTags returns a copy of the extra common tags, merged with the tags from the environment, which
+// are added to every metric.
+func (c *Config) CommonTags() map[string]string {
+	tags := make(map[string]string, len(c.extraCommonTags))
+	for k, v := range c.extraCommonTags {
+		tags[k] = v
+	}
+	return tags
+}
+
+// Logger returns the logger.
+func (c *Config) Logger() logger.Logger {
+	return c.log
+}
+
+// WriterLogInterval returns the interval at which the writers log a repeated message, such as a write
+// error, where 0 means that the messages are not rate limited.
+func (c *Config) WriterLogInterval() time.Duration {
+	return c.writerLogInterval
+}
+
+// writerLogger returns the logger passed to the writers.
+func (c *Config) writerLogger() logger.Logger {
+	if c.writerLog == nil {
+		return c.log
 	}
+	return c.writerLog
+}
+
+// stopWriterLogger stops the timers of the writer logger, once the writers which use it are closed.
+func (c *Config) stopWriterLogger() {
+	if s, ok := c.writerLog.(logger.StopLogger); ok {
+		s.Stop()
+	}
+}
 
-	mergedTags := calculateExtraCommonTags(extraCommonTags)
+// BufferSize returns the buffer size in bytes, where 0 means that buffering is disabled.
+func (c *Config) BufferSize() int {
+	return c.bufferSize
+}
 
-	return &Config{
-		location:        location,
-		extraCommonTags: mergedTags,
-		log:             calculateLogger(log),
-		bufferSize:      bufferSize,
-		flushInterval:   flushInterval,
-	}, nil
+// FlushInterval returns the interval at which buffers are flushed.
+func (c *Config) FlushInterval() time.Duration {
+	return c.flushInterval
+}
+
+// Validation reports whether meter Ids are validated when they are created by the Registry.
+func (c *Config) Validation() bool {
+	return c.validation
 }
 
 func calculateLogger(log logger.Logger) logger.Logger {
@@ -94,9 +156,25 @@ func calculateLogger(log logger.Logger) logger.Logger {
 	}
 }
 
-func calculateExtraCommonTags(extraCommonTags map[string]string) map[string]string {
+// calculateExtraCommonTags merges the common tags from all sources. From lowest to highest precedence:
+// the providers, in the order that they were configured, the extra common tags, and the tags from the
+// Netflix environment variables.
+func calculateExtraCommonTags(extraCommonTags map[string]string, providers []CommonTagProvider, log logger.Logger) map[string]string {
 	mergedTags := make(map[string]string)
 
+	for _, provider := range providers {
+		tags, err := provider.Tags()
+		if err != nil {
+			log.Errorf("Unable to get common tags from provider %s: %v", provider.Name(), err)
+			continue
+		}
+		for k, v := range tags {
+			if k != "" && v != "" {
+				mergedTags[k] = v
+			}
+		}
+	}
+
 	for k, v := range extraCommonTags {
 		// tag keys and values may not be empty strings
 		if k != "" && v != "" {
@@ -118,9 +196,9 @@ func calculateLocation(location string) (string, error) {
 		return "", fmt.Errorf("invalid spectatord output location: %s", location)
 	}
 
-	if override, ok := os.LookupEnv("SPECTATOR_OUTPUT_LOCATION"); ok {
+	if override, ok := os.LookupEnv(EnvOutputLocation); ok {
 		if !writer.IsValidOutputLocation(override) {
-			return "", fmt.Errorf("SPECTATOR_OUTPUT_LOCATION is invalid: %s", override)
+			return "", fmt.Errorf("%s is invalid: %s", EnvOutputLocation, override)
 		}
 		location = override
 	}
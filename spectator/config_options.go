@@ -0,0 +1,195 @@
+package spectator
+
+import (
+	"github.com/Netflix/spectator-go/v2/spectator/logger"
+	"log/slog"
+	"time"
+)
+
+// defaultFlushInterval is used when no flush interval is configured.
+const defaultFlushInterval = 5 * time.Second
+
+// defaultWriterLogInterval is used when no writer log interval is configured.
+const defaultWriterLogInterval = time.Minute
+
+// Option configures a Config created through NewConfigWithOptions.
+type Option func(*configOptions)
+
+// configOptions collects the raw option values, before they are resolved into a Config.
+type configOptions struct {
+	location          string
+	extraCommonTags   map[string]string
+	log               logger.Logger
+	bufferSize        int
+	flushInterval     time.Duration
+	validation        bool
+	logLevel          *logger.Level
+	tagProviders      []CommonTagProvider
+	meterFilters      []MeterFilter
+	sampleRules       []SampleRule
+	writerLogInterval *time.Duration
+}
+
+// WithLocation sets the output location. See NewConfig for the possible values. Defaults to `udp`.
+func WithLocation(location string) Option {
+	return func(o *configOptions) {
+		o.location = location
+	}
+}
+
+// WithCommonTags adds extra common tags to every metric, on top of the common tags provided by
+// spectatord. It may be used more than once, and later values for the same key take precedence.
+func WithCommonTags(tags map[string]string) Option {
+	return func(o *configOptions) {
+		if o.extraCommonTags == nil {
+			o.extraCommonTags = make(map[string]string, len(tags))
+		}
+		for k, v := range tags {
+			o.extraCommonTags[k] = v
+		}
+	}
+}
+
+// WithCommonTagProviders adds providers that discover common tags, such as NewKubernetesProvider or
+// NewBuildInfoProvider. It may be used more than once. The providers are called once, by
+// NewConfigWithOptions, and their errors are logged. When sources return the same key, the extra common
+// tags take precedence over the providers, and later providers take precedence over earlier ones.
+func WithCommonTagProviders(providers ...CommonTagProvider) Option {
+	return func(o *configOptions) {
+		o.tagProviders = append(o.tagProviders, providers...)
+	}
+}
+
+// WithLogger sets the logger. Defaults to the default logger.
+func WithLogger(log logger.Logger) Option {
+	return func(o *configOptions) {
+		o.log = log
+	}
+}
+
+// WithSlogLogger sets the logger to a slog Logger, which receives the structured attributes of the
+// messages, such as the writer location. See logger.NewSlogLogger.
+func WithSlogLogger(log *slog.Logger) Option {
+	return WithLogger(logger.NewSlogLogger(log))
+}
+
+// WithSlogHandler sets the logger to a slog Logger which writes to the handler. See WithSlogLogger.
+func WithSlogHandler(handler slog.Handler) Option {
+	return WithLogger(logger.NewSlogHandlerLogger(handler))
+}
+
+// WithBuffer sets the buffer size in bytes. See NewConfigWithBuffer for the modes of operation. Defaults
+// to 0 (disabled).
+func WithBuffer(bufferSize int) Option {
+	return func(o *configOptions) {
+		o.bufferSize = bufferSize
+	}
+}
+
+// WithFlushInterval sets the interval at which buffers are flushed. Defaults to 5 seconds.
+func WithFlushInterval(flushInterval time.Duration) Option {
+	return func(o *configOptions) {
+		o.flushInterval = flushInterval
+	}
+}
+
+// WithValidation enables validation of the meter Ids created by the Registry. Ids with an empty name, or
+// with characters that spectatord does not accept, are reported through the logger. They are still
+// written, with the invalid characters replaced. Defaults to disabled.
+func WithValidation(enabled bool) Option {
+	return func(o *configOptions) {
+		o.validation = enabled
+	}
+}
+
+// WithMeterFilters adds filters that are evaluated, in order, against the Id of every meter created by
+// the Registry, to drop or rewrite metrics without changing the instrumentation. It may be used more than
+// once. For example:
+//
+//	spectator.WithMeterFilters(
+//		spectator.DenyNames("noisy.library.*"),
+//		spectator.RenameMeter("legacy.requests", "server.requests"),
+//		spectator.DenyTags("request.id"),
+//		spectator.SampleNames("debug.*", 0.1),
+//	)
+func WithMeterFilters(filters ...MeterFilter) Option {
+	return func(o *configOptions) {
+		o.meterFilters = append(o.meterFilters, filters...)
+	}
+}
+
+// WithSampling writes a fraction of the updates of the meters matching the rules, which are evaluated in
+// order. It may be used more than once. See SamplingWriter. For example:
+//
+//	spectator.WithSampling(spectator.SampleRule{Pattern: "cache.lookups", Rate: 0.01})
+func WithSampling(rules ...SampleRule) Option {
+	return func(o *configOptions) {
+		o.sampleRules = append(o.sampleRules, rules...)
+	}
+}
+
+// WithWriterLogInterval sets the interval at which the writers log a repeated message, such as a write
+// error while spectatord is down, so that the errors do not flood the logs at the rate of the metric
+// updates. The first message is logged, followed by a summary with the number of repeats, at most once
+// per interval. Zero disables the rate limiting. Defaults to 1 minute. See logger.NewRateLimitedLogger.
+func WithWriterLogInterval(interval time.Duration) Option {
+	return func(o *configOptions) {
+		o.writerLogInterval = &interval
+	}
+}
+
+// WithLogLevel drops log messages below the level, without formatting them. Defaults to passing every
+// message to the logger.
+func WithLogLevel(level logger.Level) Option {
+	return func(o *configOptions) {
+		o.logLevel = &level
+	}
+}
+
+// NewConfigWithOptions creates a new configuration from the provided options. All options are optional,
+// and the defaults match those of NewConfig.
+//
+//	config, err := spectator.NewConfigWithOptions(
+//		spectator.WithLocation("unix"),
+//		spectator.WithCommonTags(map[string]string{"nf.app": "api"}),
+//		spectator.WithBuffer(65536),
+//	)
+func NewConfigWithOptions(opts ...Option) (*Config, error) {
+	o := &configOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	location, err := calculateLocation(o.location)
+	if err != nil {
+		return nil, err
+	}
+
+	flushInterval := o.flushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+
+	log := calculateLogger(o.log)
+	if o.logLevel != nil {
+		log = logger.NewLevelLogger(log, *o.logLevel)
+	}
+
+	writerLogInterval := defaultWriterLogInterval
+	if o.writerLogInterval != nil {
+		writerLogInterval = max(0, *o.writerLogInterval)
+	}
+
+	return &Config{
+		location:          location,
+		extraCommonTags:   calculateExtraCommonTags(o.extraCommonTags, o.tagProviders, log),
+		log:               log,
+		bufferSize:        o.bufferSize,
+		flushInterval:     flushInterval,
+		validation:        o.validation,
+		meterFilters:      o.meterFilters,
+		sampleRules:       o.sampleRules,
+		writerLog:         logger.NewRateLimitedLogger(log, writerLogInterval),
+		writerLogInterval: writerLogInterval,
+	}, nil
+}
@@ -0,0 +1,185 @@
+package spectator
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Netflix/spectator-go/v2/spectator/logger"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// captureLogger records error messages, so that tests can inspect them.
+type captureLogger struct {
+	mu     sync.Mutex
+	errors []string
+}
+
+func (l *captureLogger) Debugf(string, ...interface{}) {}
+
+func (l *captureLogger) Infof(string, ...interface{}) {}
+
+func (l *captureLogger) Errorf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, fmt.Sprintf(format, v...))
+}
+
+func (l *captureLogger) Errors() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.errors...)
+}
+
+func TestNewConfigWithOptions_Defaults(t *testing.T) {
+	config, err := NewConfigWithOptions()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if config.Location() != "udp" {
+		t.Errorf("Expected location 'udp', got '%s'", config.Location())
+	}
+	if len(config.CommonTags()) != 0 {
+		t.Errorf("Expected no common tags, got %v", config.CommonTags())
+	}
+	if _, ok := config.Logger().(*logger.DefaultLogger); !ok {
+		t.Errorf("Expected default logger, got %T", config.Logger())
+	}
+	if config.BufferSize() != 0 {
+		t.Errorf("Expected buffer size 0, got %d", config.BufferSize())
+	}
+	if config.FlushInterval() != 5*time.Second {
+		t.Errorf("Expected flush interval 5s, got %v", config.FlushInterval())
+	}
+	if config.Validation() {
+		t.Errorf("Expected validation to be disabled")
+	}
+}
+
+func TestNewConfigWithOptions_AllOptions(t *testing.T) {
+	log := &captureLogger{}
+	config, err := NewConfigWithOptions(
+		WithLocation("memory"),
+		WithCommonTags(map[string]string{"a": "1", "b": "2"}),
+		WithCommonTags(map[string]string{"b": "3"}),
+		WithLogger(log),
+		WithBuffer(4096),
+		WithFlushInterval(time.Second),
+		WithValidation(true),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if config.Location() != "memory" {
+		t.Errorf("Expected location 'memory', got '%s'", config.Location())
+	}
+	expectedTags := map[string]string{"a": "1", "b": "3"}
+	if !reflect.DeepEqual(expectedTags, config.CommonTags()) {
+		t.Errorf("Expected tags %v, got %v", expectedTags, config.CommonTags())
+	}
+	if config.Logger() != log {
+		t.Errorf("Expected configured logger")
+	}
+	if config.BufferSize() != 4096 {
+		t.Errorf("Expected buffer size 4096, got %d", config.BufferSize())
+	}
+	if config.FlushInterval() != time.Second {
+		t.Errorf("Expected flush interval 1s, got %v", config.FlushInterval())
+	}
+	if !config.Validation() {
+		t.Errorf("Expected validation to be enabled")
+	}
+}
+
+func TestNewConfigWithOptions_WriterLogInterval(t *testing.T) {
+	log := &captureLogger{}
+	config, err := NewConfigWithOptions(WithLogger(log))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.WriterLogInterval() != time.Minute {
+		t.Errorf("Expected writer log interval 1m, got %v", config.WriterLogInterval())
+	}
+
+	for i := 0; i < 3; i++ {
+		config.writerLogger().Errorf("Error writing to UDP: %s", "connection refused")
+	}
+	if len(log.Errors()) != 1 {
+		t.Errorf("Expected the repeated writer errors to be rate limited, got %v", log.Errors())
+	}
+
+	config, err = NewConfigWithOptions(WithLogger(log), WithWriterLogInterval(0))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.WriterLogInterval() != 0 || config.writerLogger() != config.Logger() {
+		t.Errorf("Expected the writer logger not to be rate limited")
+	}
+}
+
+func TestNewConfigWithOptions_SlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	config, err := NewConfigWithOptions(WithSlogHandler(handler))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := config.Logger().(*logger.SlogLogger); !ok {
+		t.Fatalf("Expected slog logger, got %T", config.Logger())
+	}
+
+	log := config.writerLogger()
+	if logger.Enabled(log, logger.LevelDebug) {
+		t.Errorf("Expected the debug level to be disabled")
+	}
+	logger.LogAttrs(log, logger.LevelDebug, "Sending line", slog.String("line", "c:a:1"))
+	logger.LogAttrs(log, logger.LevelError, "Error writing to UDP",
+		slog.String("location", "udp://127.0.0.1:1234"), slog.String("error", "connection refused"))
+
+	out := buf.String()
+	if strings.Contains(out, "Sending line") {
+		t.Errorf("Expected the debug message to be dropped, got %q", out)
+	}
+	expected := `level=ERROR msg="Error writing to UDP" location=udp://127.0.0.1:1234 error="connection refused"`
+	if !strings.Contains(out, expected) {
+		t.Errorf("Expected %q in %q", expected, out)
+	}
+}
+
+func TestNewConfigWithOptions_InvalidLocation(t *testing.T) {
+	_, err := NewConfigWithOptions(WithLocation("invalid_location"))
+	if err == nil {
+		t.Errorf("Expected error for invalid location, got nil")
+	}
+}
+
+func TestConfig_CommonTagsReturnsCopy(t *testing.T) {
+	config, _ := NewConfigWithOptions(WithCommonTags(map[string]string{"a": "1"}))
+
+	config.CommonTags()["a"] = "changed"
+
+	if config.CommonTags()["a"] != "1" {
+		t.Errorf("Expected common tags to be unchanged, got %v", config.CommonTags())
+	}
+}
+
+func TestRegistry_WithValidation(t *testing.T) {
+	log := &captureLogger{}
+	config, _ := NewConfigWithOptions(WithLocation("memory"), WithLogger(log), WithValidation(true))
+	r, _ := NewRegistry(config)
+
+	r.Counter("valid_name", map[string]string{"key": "value"})
+	if len(log.Errors()) != 0 {
+		t.Errorf("Expected no errors for a valid id, got %v", log.Errors())
+	}
+
+	r.Counter("invalid name", nil)
+	if len(log.Errors()) != 1 {
+		t.Errorf("Expected 1 error for an invalid id, got %v", log.Errors())
+	}
+}
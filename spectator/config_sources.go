@@ -0,0 +1,329 @@
+package spectator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Netflix/spectator-go/v2/spectator/logger"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by ConfigFromEnv and ConfigFromFile.
+const (
+	EnvOutputLocation = "SPECTATOR_OUTPUT_LOCATION"
+	EnvBufferSize     = "SPECTATOR_BUFFER_SIZE"
+	EnvFlushInterval  = "SPECTATOR_FLUSH_INTERVAL"
+	EnvCommonTags     = "SPECTATOR_COMMON_TAGS"
+	EnvLogLevel       = "SPECTATOR_LOG_LEVEL"
+	EnvValidation     = "SPECTATOR_VALIDATION"
+)
+
+// ConfigFromEnv creates a new configuration from environment variables:
+//
+//   - `SPECTATOR_OUTPUT_LOCATION` - Output location, see NewConfig for the possible values.
+//   - `SPECTATOR_BUFFER_SIZE`     - Buffer size in bytes, see NewConfigWithBuffer.
+//   - `SPECTATOR_FLUSH_INTERVAL`  - Buffer flush interval, as a Go duration (e.g. `5s`).
+//   - `SPECTATOR_COMMON_TAGS`     - Extra common tags, as a comma separated list (e.g. `a=b,c=d`).
+//   - `SPECTATOR_LOG_LEVEL`       - Minimum log level: `debug`, `info`, `warn` or `error`.
+//   - `SPECTATOR_VALIDATION`      - Validate meter Ids (`true` or `false`).
+//
+// The options are applied first, so environment variables take precedence over values set in code. All
+// invalid variables are reported together in the returned error.
+func ConfigFromEnv(opts ...Option) (*Config, error) {
+	envOpts, err := optionsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	all := append(append([]Option{}, opts...), envOpts...)
+	return NewConfigWithOptions(all...)
+}
+
+// ConfigFromFile creates a new configuration from a JSON file (`.json`) or a YAML file (`.yaml` or
+// `.yml`), with the keys `location`, `bufferSize`, `flushInterval`, `commonTags`, `logLevel` and
+// `validation`. For example:
+//
+//	location: unix
+//	bufferSize: 65536
+//	flushInterval: 5s
+//	logLevel: info
+//	commonTags:
+//	  nf.app: api
+//	  nf.stack: prod
+//
+// YAML support is limited to this flat structure, with `#` comments and optionally quoted values.
+//
+// The environment variables read by ConfigFromEnv are applied on top of the file, so the order of
+// precedence, from highest to lowest, is: environment variables, the file, and then the options set in
+// code. Common tags are merged across all sources, with the same precedence for duplicate keys.
+func ConfigFromFile(path string, opts ...Option) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fc *fileConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		fc, err = parseJSONConfig(data)
+	case ".yaml", ".yml":
+		fc, err = parseYAMLConfig(data)
+	default:
+		return nil, fmt.Errorf("%s: unsupported config file format, expected .json, .yaml or .yml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	fileOpts, err := fc.options()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	envOpts, err := optionsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	all := append(append(append([]Option{}, opts...), fileOpts...), envOpts...)
+	return NewConfigWithOptions(all...)
+}
+
+// fileConfig holds the values read from a config file. Unset values are nil.
+type fileConfig struct {
+	Location      *string           `json:"location"`
+	BufferSize    *int              `json:"bufferSize"`
+	FlushInterval *string           `json:"flushInterval"`
+	CommonTags    map[string]string `json:"commonTags"`
+	LogLevel      *string           `json:"logLevel"`
+	Validation    *bool             `json:"validation"`
+}
+
+func (fc *fileConfig) options() ([]Option, error) {
+	var opts []Option
+	var errs []error
+
+	if fc.Location != nil {
+		opts = append(opts, WithLocation(*fc.Location))
+	}
+	if fc.BufferSize != nil {
+		if *fc.BufferSize < 0 {
+			errs = append(errs, fmt.Errorf("bufferSize may not be negative: %d", *fc.BufferSize))
+		} else {
+			opts = append(opts, WithBuffer(*fc.BufferSize))
+		}
+	}
+	if fc.FlushInterval != nil {
+		d, err := parseFlushInterval(*fc.FlushInterval)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("flushInterval: %w", err))
+		} else {
+			opts = append(opts, WithFlushInterval(d))
+		}
+	}
+	if len(fc.CommonTags) > 0 {
+		opts = append(opts, WithCommonTags(fc.CommonTags))
+	}
+	if fc.LogLevel != nil {
+		level, err := logger.ParseLevel(*fc.LogLevel)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("logLevel: %w", err))
+		} else {
+			opts = append(opts, WithLogLevel(level))
+		}
+	}
+	if fc.Validation != nil {
+		opts = append(opts, WithValidation(*fc.Validation))
+	}
+
+	return opts, errors.Join(errs...)
+}
+
+func parseJSONConfig(data []byte) (*fileConfig, error) {
+	fc := &fileConfig{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(fc); err != nil {
+		return nil, err
+	}
+	return fc, nil
+}
+
+// parseYAMLConfig parses the flat YAML structure documented on ConfigFromFile.
+func parseYAMLConfig(data []byte) (*fileConfig, error) {
+	fc := &fileConfig{}
+	inCommonTags := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		raw := scanner.Text()
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", lineNum)
+		}
+		key = unquoteYAML(strings.TrimSpace(key))
+		value, err := parseYAMLValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+
+		indented := raw[0] == ' ' || raw[0] == '\t'
+		if indented {
+			if !inCommonTags {
+				return nil, fmt.Errorf("line %d: unexpected indentation", lineNum)
+			}
+			fc.CommonTags[key] = value
+			continue
+		}
+
+		inCommonTags = false
+		switch key {
+		case "location":
+			fc.Location = &value
+		case "bufferSize":
+			size, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: bufferSize: invalid integer %q", lineNum, value)
+			}
+			fc.BufferSize = &size
+		case "flushInterval":
+			fc.FlushInterval = &value
+		case "commonTags":
+			if value != "" {
+				return nil, fmt.Errorf("line %d: commonTags must be a nested map", lineNum)
+			}
+			inCommonTags = true
+			fc.CommonTags = make(map[string]string)
+		case "logLevel":
+			fc.LogLevel = &value
+		case "validation":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: validation: invalid boolean %q", lineNum, value)
+			}
+			fc.Validation = &enabled
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %q", lineNum, key)
+		}
+	}
+
+	return fc, scanner.Err()
+}
+
+// parseYAMLValue trims a scalar value, and removes quotes, or a trailing comment from unquoted values.
+func parseYAMLValue(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+
+	if quote := value[0]; quote == '"' || quote == '\'' {
+		end := strings.IndexByte(value[1:], quote)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", value)
+		}
+		return value[1 : end+1], nil
+	}
+
+	if idx := strings.Index(value, " #"); idx >= 0 {
+		value = strings.TrimSpace(value[:idx])
+	}
+	return value, nil
+}
+
+func unquoteYAML(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// optionsFromEnv converts the environment variables documented on ConfigFromEnv into options. The
+// output location is not included, because NewConfigWithOptions always applies it as an override.
+func optionsFromEnv() ([]Option, error) {
+	var opts []Option
+	var errs []error
+
+	if value, ok := os.LookupEnv(EnvBufferSize); ok {
+		size, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || size < 0 {
+			errs = append(errs, fmt.Errorf("%s: invalid buffer size %q, expected a non-negative integer", EnvBufferSize, value))
+		} else {
+			opts = append(opts, WithBuffer(size))
+		}
+	}
+
+	if value, ok := os.LookupEnv(EnvFlushInterval); ok {
+		d, err := parseFlushInterval(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", EnvFlushInterval, err))
+		} else {
+			opts = append(opts, WithFlushInterval(d))
+		}
+	}
+
+	if value, ok := os.LookupEnv(EnvCommonTags); ok {
+		tags, err := parseCommonTags(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", EnvCommonTags, err))
+		} else {
+			opts = append(opts, WithCommonTags(tags))
+		}
+	}
+
+	if value, ok := os.LookupEnv(EnvLogLevel); ok {
+		level, err := logger.ParseLevel(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", EnvLogLevel, err))
+		} else {
+			opts = append(opts, WithLogLevel(level))
+		}
+	}
+
+	if value, ok := os.LookupEnv(EnvValidation); ok {
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid boolean %q", EnvValidation, value))
+		} else {
+			opts = append(opts, WithValidation(enabled))
+		}
+	}
+
+	return opts, errors.Join(errs...)
+}
+
+func parseFlushInterval(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid flush interval %q, expected a positive Go duration, such as 5s", value)
+	}
+	return d, nil
+}
+
+// parseCommonTags parses a comma separated list of `key=value` pairs.
+func parseCommonTags(value string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" || v == "" {
+			return nil, fmt.Errorf("invalid tag %q, expected key=value", pair)
+		}
+		tags[k] = v
+	}
+	return tags, nil
+}
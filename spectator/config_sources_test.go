@@ -0,0 +1,203 @@
+package spectator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setEnv(t *testing.T, env map[string]string) {
+	for k, v := range env {
+		_ = os.Setenv(k, v)
+		key := k
+		t.Cleanup(func() { _ = os.Unsetenv(key) })
+	}
+}
+
+func writeConfigFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	setEnv(t, map[string]string{
+		EnvOutputLocation: "memory",
+		EnvBufferSize:     "4096",
+		EnvFlushInterval:  "2s",
+		EnvCommonTags:     "a=b, c=d",
+		EnvLogLevel:       "error",
+		EnvValidation:     "true",
+	})
+
+	config, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if config.Location() != "memory" {
+		t.Errorf("Expected location 'memory', got '%s'", config.Location())
+	}
+	if config.BufferSize() != 4096 {
+		t.Errorf("Expected buffer size 4096, got %d", config.BufferSize())
+	}
+	if config.FlushInterval() != 2*time.Second {
+		t.Errorf("Expected flush interval 2s, got %v", config.FlushInterval())
+	}
+	expectedTags := map[string]string{"a": "b", "c": "d"}
+	if !reflect.DeepEqual(expectedTags, config.CommonTags()) {
+		t.Errorf("Expected tags %v, got %v", expectedTags, config.CommonTags())
+	}
+	if !config.Validation() {
+		t.Errorf("Expected validation to be enabled")
+	}
+}
+
+func TestConfigFromEnv_ReportsAllErrors(t *testing.T) {
+	setEnv(t, map[string]string{
+		EnvBufferSize:    "lots",
+		EnvFlushInterval: "-1s",
+		EnvCommonTags:    "a",
+		EnvLogLevel:      "verbose",
+	})
+
+	_, err := ConfigFromEnv()
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	for _, name := range []string{EnvBufferSize, EnvFlushInterval, EnvCommonTags, EnvLogLevel} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected error to mention %s, got '%v'", name, err)
+		}
+	}
+}
+
+func TestConfigFromEnv_OverridesOptions(t *testing.T) {
+	setEnv(t, map[string]string{EnvBufferSize: "1024"})
+
+	config, err := ConfigFromEnv(WithLocation("memory"), WithBuffer(2048))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if config.Location() != "memory" {
+		t.Errorf("Expected location 'memory', got '%s'", config.Location())
+	}
+	if config.BufferSize() != 1024 {
+		t.Errorf("Expected buffer size 1024, got %d", config.BufferSize())
+	}
+}
+
+func TestConfigFromFile_YAML(t *testing.T) {
+	path := writeConfigFile(t, "spectator.yaml", `
+# spectator config
+location: "memory"
+bufferSize: 65536 # line buffer
+flushInterval: 1s
+validation: true
+logLevel: info
+commonTags:
+  nf.app: api
+  'nf.stack': prod
+`)
+
+	config, err := ConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if config.Location() != "memory" {
+		t.Errorf("Expected location 'memory', got '%s'", config.Location())
+	}
+	if config.BufferSize() != 65536 {
+		t.Errorf("Expected buffer size 65536, got %d", config.BufferSize())
+	}
+	if config.FlushInterval() != time.Second {
+		t.Errorf("Expected flush interval 1s, got %v", config.FlushInterval())
+	}
+	expectedTags := map[string]string{"nf.app": "api", "nf.stack": "prod"}
+	if !reflect.DeepEqual(expectedTags, config.CommonTags()) {
+		t.Errorf("Expected tags %v, got %v", expectedTags, config.CommonTags())
+	}
+	if !config.Validation() {
+		t.Errorf("Expected validation to be enabled")
+	}
+}
+
+func TestConfigFromFile_JSON(t *testing.T) {
+	path := writeConfigFile(t, "spectator.json", `{"location": "file:///tmp/metrics.log", "bufferSize": 1024, "commonTags": {"nf.app": "api"}}`)
+
+	config, err := ConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if config.Location() != "file:///tmp/metrics.log" {
+		t.Errorf("Expected file location, got '%s'", config.Location())
+	}
+	if config.BufferSize() != 1024 {
+		t.Errorf("Expected buffer size 1024, got %d", config.BufferSize())
+	}
+	if config.CommonTags()["nf.app"] != "api" {
+		t.Errorf("Expected nf.app tag, got %v", config.CommonTags())
+	}
+}
+
+func TestConfigFromFile_EnvTakesPrecedence(t *testing.T) {
+	setEnv(t, map[string]string{
+		EnvOutputLocation: "none",
+		EnvCommonTags:     "nf.stack=env",
+	})
+	path := writeConfigFile(t, "spectator.yml", "location: memory\ncommonTags:\n  nf.app: file\n  nf.stack: file\n")
+
+	config, err := ConfigFromFile(path, WithCommonTags(map[string]string{"nf.region": "code", "nf.app": "code"}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if config.Location() != "none" {
+		t.Errorf("Expected location 'none', got '%s'", config.Location())
+	}
+	expectedTags := map[string]string{"nf.region": "code", "nf.app": "file", "nf.stack": "env"}
+	if !reflect.DeepEqual(expectedTags, config.CommonTags()) {
+		t.Errorf("Expected tags %v, got %v", expectedTags, config.CommonTags())
+	}
+}
+
+func TestConfigFromFile_Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"spectator.yaml", "bufferSize: big\n", "line 1: bufferSize"},
+		{"spectator.yaml", "location: memory\n  nested: value\n", "line 2: unexpected indentation"},
+		{"spectator.yaml", "unknown: value\n", "unknown key"},
+		{"spectator.yaml", "logLevel: verbose\n", "logLevel"},
+		{"spectator.json", `{"unknown": 1}`, "unknown field"},
+		{"spectator.json", `{"flushInterval": "soon"}`, "flushInterval"},
+		{"spectator.toml", "", "unsupported config file format"},
+	}
+
+	for _, tc := range testCases {
+		path := writeConfigFile(t, tc.name, tc.content)
+		_, err := ConfigFromFile(path)
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("Expected error containing '%s' for %s, got '%v'", tc.expected, tc.content, err)
+		}
+	}
+}
+
+func TestConfigFromFile_InvalidLocation(t *testing.T) {
+	path := writeConfigFile(t, "spectator.yaml", "location: nowhere\n")
+
+	if _, err := ConfigFromFile(path); err == nil {
+		t.Errorf("Expected error for invalid location, got nil")
+	}
+}
@@ -0,0 +1,329 @@
+package spectator
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Netflix/spectator-go/v2/spectator/meter"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDebugWindow is the window of the DebugHandler, when none is given.
+const defaultDebugWindow = 5 * time.Minute
+
+// debugBuckets is the number of slices of the window, which are used to compute the rates.
+const debugBuckets = 12
+
+// DebugSeries is a series listed by the DebugHandler.
+type DebugSeries struct {
+	// Type is the name of the meter type, such as counter or percentile-timer.
+	Type string `json:"type"`
+	// Id is the Id of the series, in the format of the spectatord protocol.
+	Id   string            `json:"id"`
+	Name string            `json:"name"`
+	Tags map[string]string `json:"tags"`
+	// LastValue is the last value written. For timers, it is in seconds.
+	LastValue float64 `json:"lastValue"`
+	// Rate is the sum of the increments per second for counters, and the number of updates per second for
+	// the other meters, over the window of the handler.
+	Rate      float64   `json:"rate"`
+	LastWrite time.Time `json:"lastWrite"`
+	// P50 and P99 are estimated for the percentile timers and percentile distribution summaries, from the
+	// values written since the series was first seen.
+	P50 *float64 `json:"p50,omitempty"`
+	P99 *float64 `json:"p99,omitempty"`
+}
+
+// DebugHandler is an http.Handler, which lists the series written by a Registry in the last minutes, to
+// check whether a meter is actually updated. It receives a copy of the lines written by the meters of the
+// registry, and aggregates them in memory.
+//
+//	h, err := spectator.NewDebugHandler(registry, 5*time.Minute)
+//	if err != nil {
+//		return err
+//	}
+//	defer h.Close()
+//	http.Handle("/debug/metrics", h)
+//
+// The page is HTML, or JSON when the `format=json` query parameter is set, or when the request accepts
+// application/json. The series are filtered by the `prefix` query parameter, which matches the start of
+// the name, and by `tag=key:value` query parameters, which may be repeated.
+//
+// Every write goes through the handler, with a lock, so it is meant for debugging, not for production
+// traffic.
+type DebugHandler struct {
+	state  *registryState
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	series    map[debugKey]*debugSeries
+	lastPrune time.Time
+}
+
+type debugKey struct {
+	symbol string
+	id     string
+}
+
+type debugBucket struct {
+	slot  int64
+	count uint64
+	sum   float64
+}
+
+type debugSeries struct {
+	id         *meter.Id
+	lastValue  float64
+	firstWrite time.Time
+	lastWrite  time.Time
+	buckets    [debugBuckets]debugBucket
+	histogram  *meter.PercentileHistogram
+}
+
+// NewDebugHandler creates a handler, which lists the series written by the registry in the last window,
+// or in the last 5 minutes if the window is not positive. The window is at least a second. The registry
+// must have been created by NewRegistry, and the handler receives the writes of all its scoped views.
+// Close the handler to stop receiving the writes.
+func NewDebugHandler(r Registry, window time.Duration) (*DebugHandler, error) {
+	sr, ok := r.(*spectatordRegistry)
+	if !ok {
+		return nil, fmt.Errorf("debug handler requires a registry created by NewRegistry, got %T", r)
+	}
+	if window <= 0 {
+		window = defaultDebugWindow
+	}
+	window = max(window, time.Second)
+
+	h := &DebugHandler{
+		state:  sr.state,
+		window: window,
+		now:    time.Now,
+		series: make(map[debugKey]*debugSeries),
+	}
+	sr.state.writer.addTee(h)
+	return h, nil
+}
+
+func (h *DebugHandler) Write(line string) {
+	h.record(line)
+}
+
+func (h *DebugHandler) WriteBytes(line []byte) {
+	h.WriteString(string(line))
+}
+
+func (h *DebugHandler) WriteString(line string) {
+	for _, l := range strings.Split(line, "\n") {
+		h.record(l)
+	}
+}
+
+// Close stops copying the writes of the registry to the handler. It does not close the registry.
+func (h *DebugHandler) Close() error {
+	h.state.writer.removeTee(h)
+	return nil
+}
+
+// slot returns the number of the slice of the window for the time.
+func (h *DebugHandler) slot(t time.Time) int64 {
+	return t.UnixNano() / int64(h.window/debugBuckets)
+}
+
+func (h *DebugHandler) record(line string) {
+	symbol, rest, ok := strings.Cut(line, ":")
+	if !ok {
+		return
+	}
+	i := strings.LastIndexByte(rest, ':')
+	if i < 0 {
+		return
+	}
+	id, value := rest[:i], rest[i+1:]
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
+	}
+
+	now := h.now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := debugKey{symbol: symbol, id: id}
+	s, ok := h.series[key]
+	if !ok {
+		parsed, err := meter.ParseId(id)
+		if err != nil {
+			return
+		}
+		h.maybePrune(now)
+		// the key must not retain the line
+		key = debugKey{symbol: strings.Clone(symbol), id: strings.Clone(id)}
+		s = &debugSeries{id: parsed, firstWrite: now}
+		if symbol == "T" || symbol == "D" {
+			s.histogram = meter.NewPercentileHistogram()
+		}
+		h.series[key] = s
+	}
+
+	s.lastValue = v
+	s.lastWrite = now
+	slot := h.slot(now)
+	b := &s.buckets[slot%debugBuckets]
+	if b.slot != slot {
+		*b = debugBucket{slot: slot}
+	}
+	b.count++
+	b.sum += v
+	if s.histogram != nil {
+		s.histogram.Record(toHistogramValue(symbol, v))
+	}
+}
+
+// maybePrune removes the series which were not written during the window, at most once per slice of the
+// window. It must be called with the lock held.
+func (h *DebugHandler) maybePrune(now time.Time) {
+	if now.Sub(h.lastPrune) < h.window/debugBuckets {
+		return
+	}
+	h.lastPrune = now
+	for k, s := range h.series {
+		if now.Sub(s.lastWrite) > h.window {
+			delete(h.series, k)
+		}
+	}
+}
+
+// rate computes the rate of the series over the window. It must be called with the lock held.
+func (h *DebugHandler) rate(symbol string, s *debugSeries, now time.Time) float64 {
+	oldest := h.slot(now) - debugBuckets
+	var total float64
+	for _, b := range s.buckets {
+		if b.slot > oldest {
+			if symbol == "c" {
+				total += b.sum
+			} else {
+				total += float64(b.count)
+			}
+		}
+	}
+
+	elapsed := min(h.window, now.Sub(s.firstWrite))
+	if elapsed < time.Second {
+		elapsed = time.Second
+	}
+	return total / elapsed.Seconds()
+}
+
+// Series returns the series written during the window, with a name starting with the prefix, and with the
+// tags, sorted by Id.
+func (h *DebugHandler) Series(prefix string, tags map[string]string) []DebugSeries {
+	now := h.now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var result []DebugSeries
+	for k, s := range h.series {
+		if now.Sub(s.lastWrite) > h.window || !debugMatches(s.id, prefix, tags) {
+			continue
+		}
+		series := DebugSeries{
+			Type:      MeterType(k.symbol),
+			Id:        k.id,
+			Name:      s.id.Name(),
+			Tags:      s.id.Tags(),
+			LastValue: s.lastValue,
+			Rate:      h.rate(k.symbol, s, now),
+			LastWrite: s.lastWrite,
+		}
+		if s.histogram != nil {
+			pcts := s.histogram.Percentiles(50, 99)
+			p50, p99 := fromHistogramValue(k.symbol, pcts[0]), fromHistogramValue(k.symbol, pcts[1])
+			series.P50, series.P99 = &p50, &p99
+		}
+		result = append(result, series)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Id != result[j].Id {
+			return result[i].Id < result[j].Id
+		}
+		return result[i].Type < result[j].Type
+	})
+	return result
+}
+
+func debugMatches(id *meter.Id, prefix string, tags map[string]string) bool {
+	if !strings.HasPrefix(id.Name(), prefix) {
+		return false
+	}
+	for k, v := range tags {
+		if actual, ok := id.Tag(k); !ok || actual != v {
+			return false
+		}
+	}
+	return true
+}
+
+// debugPage is the data of the HTML page.
+type debugPage struct {
+	Window time.Duration
+	Prefix string
+	Tags   []string
+	Series []DebugSeries
+}
+
+var debugTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Spectator metrics</title></head>
+<body>
+<h1>Metrics written in the last {{.Window}}</h1>
+<form method="get">
+<label>Name prefix <input name="prefix" value="{{.Prefix}}"></label>
+<label>Tag <input name="tag" placeholder="key:value" value="{{range $i, $t := .Tags}}{{if $i}} {{end}}{{$t}}{{end}}"></label>
+<input type="submit" value="Filter">
+</form>
+<table>
+<tr><th>Type</th><th>Id</th><th>Last value</th><th>Rate (/s)</th><th>p50</th><th>p99</th><th>Last write</th></tr>
+{{range .Series}}<tr><td>{{.Type}}</td><td>{{.Id}}</td><td>{{.LastValue}}</td><td>{{printf "%.3f" .Rate}}</td><td>{{with .P50}}{{.}}{{end}}</td><td>{{with .P99}}{{.}}{{end}}</td><td>{{.LastWrite.Format "2006-01-02T15:04:05.000Z07:00"}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func (h *DebugHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	prefix := query.Get("prefix")
+	tags := make(map[string]string)
+	var tagParams []string
+	for _, param := range query["tag"] {
+		for _, tag := range strings.Fields(param) {
+			k, v, ok := strings.Cut(tag, ":")
+			if !ok || k == "" {
+				http.Error(w, fmt.Sprintf("invalid tag filter %q, expected key:value", tag), http.StatusBadRequest)
+				return
+			}
+			tags[k] = v
+			tagParams = append(tagParams, tag)
+		}
+	}
+
+	series := h.Series(prefix, tags)
+
+	if query.Get("format") == "json" || strings.Contains(req.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		if series == nil {
+			series = []DebugSeries{}
+		}
+		_ = json.NewEncoder(w).Encode(series)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = debugTemplate.Execute(w, debugPage{Window: h.window, Prefix: prefix, Tags: tagParams, Series: series})
+}
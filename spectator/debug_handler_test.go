@@ -0,0 +1,162 @@
+package spectator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestDebugHandler creates a handler on a memory registry, with a clock controlled by the test.
+func newTestDebugHandler(t *testing.T) (Registry, *DebugHandler, *time.Time) {
+	r := NewTestRegistry()
+	h, err := NewDebugHandler(r, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = h.Close() })
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+	return r, h, &now
+}
+
+func TestDebugHandler_Series(t *testing.T) {
+	r, h, now := newTestDebugHandler(t)
+
+	c := r.Counter("server.requests", map[string]string{"status": "200"})
+	for i := 0; i < 30; i++ {
+		c.Add(2)
+		*now = now.Add(time.Second)
+	}
+	r.Gauge("server.queue", nil).Set(5)
+
+	series := h.Series("", nil)
+	if len(series) != 2 {
+		t.Fatalf("Expected 2 series, got %v", series)
+	}
+
+	queue, requests := series[0], series[1]
+	if queue.Type != "gauge" || queue.Id != "server.queue" || queue.LastValue != 5 {
+		t.Errorf("Unexpected gauge series %+v", queue)
+	}
+	if requests.Type != "counter" || requests.Name != "server.requests" || requests.Tags["status"] != "200" {
+		t.Errorf("Unexpected counter series %+v", requests)
+	}
+	if requests.LastValue != 2 {
+		t.Errorf("Expected last value 2, got %v", requests.LastValue)
+	}
+	if requests.Rate != 2 {
+		t.Errorf("Expected a rate of 2/s, got %v", requests.Rate)
+	}
+	if !requests.LastWrite.Equal(now.Add(-time.Second)) {
+		t.Errorf("Expected last write at %v, got %v", now.Add(-time.Second), requests.LastWrite)
+	}
+}
+
+func TestDebugHandler_Window(t *testing.T) {
+	r, h, now := newTestDebugHandler(t)
+
+	r.Counter("old", nil).Increment()
+	*now = now.Add(2 * time.Minute)
+	r.Counter("new", nil).Increment()
+
+	series := h.Series("", nil)
+	if len(series) != 1 || series[0].Name != "new" {
+		t.Errorf("Expected only the series written in the window, got %v", series)
+	}
+	if len(h.series) != 1 {
+		t.Errorf("Expected the old series to be pruned, got %d series", len(h.series))
+	}
+}
+
+func TestDebugHandler_Percentiles(t *testing.T) {
+	r, h, _ := newTestDebugHandler(t)
+
+	pt := r.PercentileTimer("server.latency", nil)
+	for i := 1; i <= 1000; i++ {
+		pt.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	series := h.Series("server.latency", nil)
+	if len(series) != 1 || series[0].P99 == nil {
+		t.Fatalf("Expected percentiles for the percentile timer, got %v", series)
+	}
+	if p99 := *series[0].P99; p99 < 0.9 || p99 > 1.1 {
+		t.Errorf("Expected p99 to be about 0.99s, got %v", p99)
+	}
+}
+
+func TestDebugHandler_ServeJSON(t *testing.T) {
+	r, h, _ := newTestDebugHandler(t)
+
+	r.Counter("server.requests", map[string]string{"status": "200"}).Increment()
+	r.Counter("server.requests", map[string]string{"status": "500"}).Increment()
+	r.Counter("client.requests", map[string]string{"status": "200"}).Increment()
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/metrics?format=json&prefix=server.&tag=status:500", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	var series []DebugSeries
+	if err := json.Unmarshal(rec.Body.Bytes(), &series); err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 1 || series[0].Id != "server.requests,status=500" {
+		t.Errorf("Expected the filtered series, got %v", series)
+	}
+}
+
+func TestDebugHandler_ServeHTML(t *testing.T) {
+	r, h, _ := newTestDebugHandler(t)
+
+	r.(ScopedRegistry).Scoped("lib", nil).Counter("requests", map[string]string{"method": "<GET>"}).Increment()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/metrics", nil))
+
+	body := rec.Body.String()
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("Expected an HTML page, got %s", rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(body, "lib.requests,method=_GET_") {
+		t.Errorf("Expected the series of the scoped view in the page, got %s", body)
+	}
+}
+
+func TestDebugHandler_InvalidTagFilter(t *testing.T) {
+	_, h, _ := newTestDebugHandler(t)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/metrics?tag=status", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestDebugHandler_Close(t *testing.T) {
+	r, h, _ := newTestDebugHandler(t)
+
+	_ = h.Close()
+	r.Counter("after.close", nil).Increment()
+
+	if series := h.Series("", nil); len(series) != 0 {
+		t.Errorf("Expected no series after close, got %v", series)
+	}
+}
+
+// wrappedRegistry is a Registry which is not created by NewRegistry.
+type wrappedRegistry struct {
+	Registry
+}
+
+func TestDebugHandler_UnsupportedRegistry(t *testing.T) {
+	if _, err := NewDebugHandler(wrappedRegistry{NewTestRegistry()}, time.Minute); err == nil {
+		t.Error("Expected an error for a registry not created by NewRegistry")
+	}
+}
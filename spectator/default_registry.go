@@ -0,0 +1,250 @@
+package spectator
+
+import (
+	"github.com/Netflix/spectator-go/v2/spectator/logger"
+	"github.com/Netflix/spectator-go/v2/spectator/meter"
+	"github.com/Netflix/spectator-go/v2/spectator/writer"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// registryHolder allows a Registry interface value to be stored in an atomic.Pointer.
+type registryHolder struct {
+	registry Registry
+}
+
+var (
+	defaultRegistry     atomic.Pointer[registryHolder]
+	noopRegistryOnce    sync.Once
+	noopDefaultRegistry Registry
+)
+
+// noopRegistry returns the registry used until the application calls SetDefault, which discards all writes.
+func noopRegistry() Registry {
+	noopRegistryOnce.Do(func() {
+		config := &Config{
+			location:        "none",
+			extraCommonTags: map[string]string{},
+			log:             logger.NewDefaultLogger(),
+			flushInterval:   defaultFlushInterval,
+		}
+		noopDefaultRegistry = newRegistryWithWriter(config, &writer.NoopWriter{})
+	})
+	return noopDefaultRegistry
+}
+
+// Default returns the default registry, used by the package-level helpers, such as Counter. Until the
+// application calls SetDefault, it is a registry which discards all writes.
+func Default() Registry {
+	return currentRegistry(defaultRegistry.Load())
+}
+
+// currentRegistry returns the registry of the holder, or the registry which discards all writes.
+func currentRegistry(holder *registryHolder) Registry {
+	if holder == nil {
+		return noopRegistry()
+	}
+	return holder.registry
+}
+
+// SetDefault replaces the default registry, so that libraries can emit metrics without the application
+// passing a Registry to them. Setting nil restores the registry which discards all writes. It is safe to
+// call concurrently with the package-level helpers.
+//
+// The meters created by the package-level helpers write to the default registry at the time of each
+// update, so the meters created before SetDefault start writing to the new registry, with the extra common
+// tags and meter filters of the new registry. MeterId returns the Id computed when the meter was created.
+// The application remains responsible for closing the registry.
+func SetDefault(r Registry) {
+	if r == nil {
+		defaultRegistry.Store(nil)
+		return
+	}
+	defaultRegistry.Store(&registryHolder{registry: r})
+}
+
+// defaultWriter forwards the writes of a meter created by the package-level helpers to the current default
+// registry. The Id of the meter, with the extra common tags, and the meter filter decision are resolved
+// against the current default registry, and its configuration, and recomputed after they change, so the
+// meters created before SetDefault get the extra common tags and meter filters of the application.
+type defaultWriter struct {
+	name string
+	tags map[string]string
+	// base is the spectatordId of the Id the meter was created with, which the lines are written with.
+	base     string
+	resolved atomic.Pointer[defaultResolution]
+}
+
+// defaultResolution is the Id and writer of a meter for a default registry, and its configuration.
+type defaultResolution struct {
+	holder   *registryHolder
+	config   *Config
+	registry Registry
+	id       string
+	// w is the writer of the meter, or nil to write to the current writer of other Registry
+	// implementations.
+	w writer.Writer
+}
+
+func (res *defaultResolution) writer() writer.Writer {
+	if res.w == nil {
+		return res.registry.GetWriter()
+	}
+	return res.w
+}
+
+// newDefaultMeter creates the writer of a meter, and resolves its Id with the current default registry.
+func defaultMeter(name string, tags map[string]string) (*meter.Id, writer.Writer) {
+	copied := make(map[string]string, len(tags))
+	for k, v := range tags {
+		copied[k] = v
+	}
+	d := &defaultWriter{name: name, tags: copied}
+	id, res := d.resolve(defaultRegistry.Load())
+	d.base = res.id
+	d.resolved.Store(res)
+	return id, d
+}
+
+// resolve builds the Id with the registry of the holder, and applies its meter filters.
+func (d *defaultWriter) resolve(holder *registryHolder) (*meter.Id, *defaultResolution) {
+	r := currentRegistry(holder)
+	id := r.NewId(d.name, d.tags)
+	res := &defaultResolution{holder: holder, registry: r}
+	if sr, ok := r.(*spectatordRegistry); ok {
+		// the writer of the meters follows Reconfigure, and drains the writes before the swap
+		res.config = sr.config()
+		id, res.w = sr.filter(id)
+	}
+	res.id = id.SpectatordId()
+	return id, res
+}
+
+// current returns the resolution for the current default registry, and its configuration.
+func (d *defaultWriter) current() *defaultResolution {
+	holder := defaultRegistry.Load()
+	res := d.resolved.Load()
+	if res.holder == holder {
+		sr, ok := res.registry.(*spectatordRegistry)
+		if !ok || sr.config() == res.config {
+			return res
+		}
+	}
+	_, res = d.resolve(holder)
+	d.resolved.Store(res)
+	return res
+}
+
+// rewrite replaces the Id of the line, formatted as `symbol:id:value`, with the resolved Id.
+func (d *defaultWriter) rewrite(line string, res *defaultResolution) string {
+	if res.id == d.base {
+		return line
+	}
+	symbol, rest, ok := strings.Cut(line, ":")
+	if !ok {
+		return line
+	}
+	value, ok := strings.CutPrefix(rest, d.base+":")
+	if !ok {
+		return line
+	}
+	return symbol + ":" + res.id + ":" + value
+}
+
+func (d *defaultWriter) Write(line string) {
+	res := d.current()
+	res.writer().Write(d.rewrite(line, res))
+}
+
+func (d *defaultWriter) WriteBytes(line []byte) {
+	res := d.current()
+	if res.id == d.base {
+		res.writer().WriteBytes(line)
+		return
+	}
+	res.writer().WriteString(d.rewrite(string(line), res))
+}
+
+func (d *defaultWriter) WriteString(line string) {
+	res := d.current()
+	res.writer().WriteString(d.rewrite(line, res))
+}
+
+// Close does nothing, because the writer is owned by the default registry.
+func (*defaultWriter) Close() error {
+	return nil
+}
+
+// AgeGauge creates an age gauge with the default registry. See SetDefault.
+func AgeGauge(name string, tags map[string]string) *meter.AgeGauge {
+	return meter.NewAgeGauge(defaultMeter(name, tags))
+}
+
+// Counter creates a counter with the default registry. See SetDefault.
+func Counter(name string, tags map[string]string) *meter.Counter {
+	return meter.NewCounter(defaultMeter(name, tags))
+}
+
+// DistributionSummary creates a distribution summary with the default registry. See SetDefault.
+func DistributionSummary(name string, tags map[string]string) *meter.DistributionSummary {
+	return meter.NewDistributionSummary(defaultMeter(name, tags))
+}
+
+// Gauge creates a gauge with the default registry. See SetDefault.
+func Gauge(name string, tags map[string]string) *meter.Gauge {
+	return meter.NewGauge(defaultMeter(name, tags))
+}
+
+// GaugeWithTTL creates a gauge with a ttl with the default registry. See SetDefault.
+func GaugeWithTTL(name string, tags map[string]string, ttl time.Duration) *meter.Gauge {
+	id, w := defaultMeter(name, tags)
+	return meter.NewGaugeWithTTL(id, w, ttl)
+}
+
+// MaxGauge creates a max gauge with the default registry. See SetDefault.
+func MaxGauge(name string, tags map[string]string) *meter.MaxGauge {
+	return meter.NewMaxGauge(defaultMeter(name, tags))
+}
+
+// MonotonicCounter creates a monotonic counter with the default registry. See SetDefault.
+func MonotonicCounter(name string, tags map[string]string) *meter.MonotonicCounter {
+	return meter.NewMonotonicCounter(defaultMeter(name, tags))
+}
+
+// MonotonicCounterUint creates a monotonic counter for uint64 values with the default registry. See
+// SetDefault.
+func MonotonicCounterUint(name string, tags map[string]string) *meter.MonotonicCounterUint {
+	return meter.NewMonotonicCounterUint(defaultMeter(name, tags))
+}
+
+// PercentileDistributionSummary creates a percentile distribution summary with the default registry. See
+// SetDefault.
+func PercentileDistributionSummary(name string, tags map[string]string) *meter.PercentileDistributionSummary {
+	return meter.NewPercentileDistributionSummary(defaultMeter(name, tags))
+}
+
+// PercentileDistributionSummaryWithRange creates a percentile distribution summary, which clamps the
+// recorded values into the range, with the default registry. See SetDefault.
+func PercentileDistributionSummaryWithRange(name string, tags map[string]string, min int64, max int64) *meter.PercentileDistributionSummary {
+	id, w := defaultMeter(name, tags)
+	return meter.NewPercentileDistributionSummaryWithRange(id, w, min, max)
+}
+
+// PercentileTimer creates a percentile timer with the default registry. See SetDefault.
+func PercentileTimer(name string, tags map[string]string) *meter.PercentileTimer {
+	return meter.NewPercentileTimer(defaultMeter(name, tags))
+}
+
+// PercentileTimerWithRange creates a percentile timer, which clamps the recorded values into the range,
+// with the default registry. See SetDefault.
+func PercentileTimerWithRange(name string, tags map[string]string, min time.Duration, max time.Duration) *meter.PercentileTimer {
+	id, w := defaultMeter(name, tags)
+	return meter.NewPercentileTimerWithRange(id, w, min, max)
+}
+
+// Timer creates a timer with the default registry. See SetDefault.
+func Timer(name string, tags map[string]string) *meter.Timer {
+	return meter.NewTimer(defaultMeter(name, tags))
+}
@@ -0,0 +1,127 @@
+package spectator
+
+import (
+	"fmt"
+	"github.com/Netflix/spectator-go/v2/spectator/writer"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDefaultRegistry_NoopUntilSet(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+
+	if _, ok := Default().GetWriter().(*writer.NoopWriter); !ok {
+		t.Fatalf("Expected the default registry to use a NoopWriter, got %T", Default().GetWriter())
+	}
+
+	counter := Counter("test_counter", nil)
+	counter.Increment()
+
+	r := NewTestRegistry()
+	mw := r.GetWriter().(*writer.MemoryWriter)
+	SetDefault(r)
+
+	// the counter created before SetDefault writes to the new registry
+	counter.Increment()
+	Gauge("test_gauge", nil).Set(1)
+
+	expected := []string{"c:test_counter:1", "g:test_gauge:1.000000"}
+	if len(mw.Lines()) != 2 || mw.Lines()[0] != expected[0] || mw.Lines()[1] != expected[1] {
+		t.Errorf("Expected %v, got %v", expected, mw.Lines())
+	}
+
+	SetDefault(nil)
+	counter.Increment()
+	if len(mw.Lines()) != 2 {
+		t.Errorf("Expected no writes after the default was reset, got %v", mw.Lines())
+	}
+}
+
+func TestDefaultRegistry_AppliesCommonTagsAndFilters(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+
+	config, _ := NewConfigWithOptions(
+		WithLocation("memory"),
+		WithCommonTags(map[string]string{"nf.app": "api"}),
+		WithMeterFilters(DenyNames("noisy.*")),
+	)
+	r, _ := NewRegistry(config)
+	mw := r.GetWriter().(*writer.MemoryWriter)
+	SetDefault(r)
+
+	Counter("noisy.requests", nil).Increment()
+	Counter("server.requests", nil).Increment()
+
+	expected := "c:server.requests,nf.app=api:1"
+	if len(mw.Lines()) != 1 || mw.Lines()[0] != expected {
+		t.Errorf("Expected '%s', got %v", expected, mw.Lines())
+	}
+}
+
+func TestDefaultRegistry_AppliesCommonTagsAndFiltersToExistingMeters(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+
+	// library meters are created before the application sets the default registry
+	noisy := Counter("noisy.requests", nil)
+	requests := Counter("server.requests", map[string]string{"status": "200"})
+	timer := Timer("server.latency", nil)
+
+	config, _ := NewConfigWithOptions(
+		WithLocation("memory"),
+		WithCommonTags(map[string]string{"nf.app": "api"}),
+		WithMeterFilters(DenyNames("noisy.*")),
+	)
+	r, _ := NewRegistry(config)
+	mw := r.GetWriter().(*writer.MemoryWriter)
+	SetDefault(r)
+
+	noisy.Increment()
+	requests.Increment()
+	timer.Record(time.Second)
+
+	expected := []string{"c:server.requests,nf.app=api,status=200:1", "t:server.latency,nf.app=api:1.000000"}
+	if fmt.Sprint(mw.Lines()) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, mw.Lines())
+	}
+
+	// the Id is resolved again after Reconfigure
+	config, _ = NewConfigWithOptions(WithLocation("memory"), WithCommonTags(map[string]string{"nf.app": "web"}))
+	if err := r.(ReconfigurableRegistry).Reconfigure(config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mw = r.GetWriter().(*writer.MemoryWriter)
+	noisy.Increment()
+
+	expected = []string{"c:noisy.requests,nf.app=web:1"}
+	if fmt.Sprint(mw.Lines()) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, mw.Lines())
+	}
+}
+
+func TestDefaultRegistry_Concurrent(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+
+	r := NewTestRegistry()
+	counter := Counter("test_counter", nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				counter.Increment()
+				Timer("test_timer", nil).Record(1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				SetDefault(r)
+				SetDefault(nil)
+			}
+		}()
+	}
+	wg.Wait()
+}
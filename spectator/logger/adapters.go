@@ -0,0 +1,197 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// SlogLogger adapts a *slog.Logger to Logger. The messages of the levels which
+// are disabled in the slog Logger are not formatted, and the structured
+// attributes are passed to the slog Logger as is.
+type SlogLogger struct {
+	log *slog.Logger
+}
+
+// NewSlogLogger creates a Logger which writes to the slog Logger, or to the
+// default slog Logger, if it is nil.
+func NewSlogLogger(log *slog.Logger) *SlogLogger {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &SlogLogger{log: log}
+}
+
+// NewSlogHandlerLogger creates a Logger which writes to the slog Handler.
+func NewSlogHandlerLogger(handler slog.Handler) *SlogLogger {
+	return NewSlogLogger(slog.New(handler))
+}
+
+func (l *SlogLogger) Debugf(format string, v ...interface{}) {
+	logf(l.log, LevelDebug, format, v)
+}
+
+func (l *SlogLogger) Infof(format string, v ...interface{}) {
+	logf(l.log, LevelInfo, format, v)
+}
+
+func (l *SlogLogger) Warnf(format string, v ...interface{}) {
+	logf(l.log, LevelWarn, format, v)
+}
+
+func (l *SlogLogger) Errorf(format string, v ...interface{}) {
+	logf(l.log, LevelError, format, v)
+}
+
+func (l *SlogLogger) Enabled(level Level) bool {
+	return l.log.Enabled(context.Background(), level.slogLevel())
+}
+
+func (l *SlogLogger) LogAttrs(level Level, msg string, attrs ...slog.Attr) {
+	l.log.LogAttrs(context.Background(), level.slogLevel(), msg, attrs...)
+}
+
+// KeyValueLogger is the interface of the loggers with structured methods which
+// take alternating keys and values, such as the zap SugaredLogger.
+type KeyValueLogger interface {
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+}
+
+// keyValueLogger adapts a KeyValueLogger to Logger.
+type keyValueLogger struct {
+	log KeyValueLogger
+}
+
+// NewKeyValueLogger creates a Logger which passes the structured attributes to
+// the KeyValueLogger, as alternating keys and values. For example, with zap:
+//
+//	log := logger.NewKeyValueLogger(zapLogger.Sugar())
+func NewKeyValueLogger(log KeyValueLogger) Logger {
+	return &keyValueLogger{log: log}
+}
+
+func (l *keyValueLogger) Debugf(format string, v ...interface{}) {
+	l.log.Debugw(fmt.Sprintf(format, v...))
+}
+
+func (l *keyValueLogger) Infof(format string, v ...interface{}) {
+	l.log.Infow(fmt.Sprintf(format, v...))
+}
+
+func (l *keyValueLogger) Warnf(format string, v ...interface{}) {
+	l.log.Warnw(fmt.Sprintf(format, v...))
+}
+
+func (l *keyValueLogger) Errorf(format string, v ...interface{}) {
+	l.log.Errorw(fmt.Sprintf(format, v...))
+}
+
+func (l *keyValueLogger) Enabled(Level) bool {
+	return true
+}
+
+func (l *keyValueLogger) LogAttrs(level Level, msg string, attrs ...slog.Attr) {
+	kvs := make([]interface{}, 0, 2*len(attrs))
+	for _, a := range attrs {
+		kvs = append(kvs, a.Key, a.Value.Any())
+	}
+	switch {
+	case level <= LevelDebug:
+		l.log.Debugw(msg, kvs...)
+	case level == LevelInfo:
+		l.log.Infow(msg, kvs...)
+	case level == LevelWarn:
+		l.log.Warnw(msg, kvs...)
+	default:
+		l.log.Errorw(msg, kvs...)
+	}
+}
+
+// FieldEntry is the interface of the entries of the loggers with fields, such
+// as the logrus Entry, where E is the type of the entry, such as *logrus.Entry.
+type FieldEntry[E any] interface {
+	WithField(key string, value interface{}) E
+	Debug(args ...interface{})
+	Info(args ...interface{})
+	Warn(args ...interface{})
+	Error(args ...interface{})
+}
+
+// FieldLogger is the interface of the loggers with fields, such as the logrus
+// Logger, where E is the type of the entries, such as *logrus.Entry.
+type FieldLogger[E any] interface {
+	WithField(key string, value interface{}) E
+	Debugf(format string, v ...interface{})
+	Infof(format string, v ...interface{})
+	Warnf(format string, v ...interface{})
+	Errorf(format string, v ...interface{})
+}
+
+// fieldLogger adapts a FieldLogger to Logger.
+type fieldLogger[E FieldEntry[E]] struct {
+	log FieldLogger[E]
+}
+
+// NewFieldLogger creates a Logger which passes the structured attributes to the
+// FieldLogger, as fields. The type of the entries must be given. For example,
+// with logrus:
+//
+//	log := logger.NewFieldLogger[*logrus.Entry](logrus.StandardLogger())
+func NewFieldLogger[E FieldEntry[E]](log FieldLogger[E]) Logger {
+	return &fieldLogger[E]{log: log}
+}
+
+func (l *fieldLogger[E]) Debugf(format string, v ...interface{}) {
+	l.log.Debugf(format, v...)
+}
+
+func (l *fieldLogger[E]) Infof(format string, v ...interface{}) {
+	l.log.Infof(format, v...)
+}
+
+func (l *fieldLogger[E]) Warnf(format string, v ...interface{}) {
+	l.log.Warnf(format, v...)
+}
+
+func (l *fieldLogger[E]) Errorf(format string, v ...interface{}) {
+	l.log.Errorf(format, v...)
+}
+
+func (l *fieldLogger[E]) Enabled(Level) bool {
+	return true
+}
+
+func (l *fieldLogger[E]) LogAttrs(level Level, msg string, attrs ...slog.Attr) {
+	if len(attrs) == 0 {
+		// without fields, there is no entry to log to
+		switch {
+		case level <= LevelDebug:
+			l.log.Debugf("%s", msg)
+		case level == LevelInfo:
+			l.log.Infof("%s", msg)
+		case level == LevelWarn:
+			l.log.Warnf("%s", msg)
+		default:
+			l.log.Errorf("%s", msg)
+		}
+		return
+	}
+
+	entry := l.log.WithField(attrs[0].Key, attrs[0].Value.Any())
+	for _, a := range attrs[1:] {
+		entry = entry.WithField(a.Key, a.Value.Any())
+	}
+	switch {
+	case level <= LevelDebug:
+		entry.Debug(msg)
+	case level == LevelInfo:
+		entry.Info(msg)
+	case level == LevelWarn:
+		entry.Warn(msg)
+	default:
+		entry.Error(msg)
+	}
+}
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// countingStringer counts how many times it is formatted.
+type countingStringer struct {
+	n int
+}
+
+func (s *countingStringer) String() string {
+	s.n++
+	return "value"
+}
+
+func TestSlogLogger_SkipsFormattingWhenDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewSlogHandlerLogger(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	s := &countingStringer{}
+	l.Debugf("Sending line: %s", s)
+	l.Infof("Sending line: %s", s)
+	if s.n != 0 || buf.Len() != 0 {
+		t.Errorf("Expected disabled messages not to be formatted, got %d calls and %q", s.n, buf.String())
+	}
+	if l.Enabled(LevelInfo) || !l.Enabled(LevelWarn) {
+		t.Errorf("Expected only warn and error to be enabled")
+	}
+
+	l.Warnf("queue full: %s", s)
+	l.LogAttrs(LevelError, "Error writing to UDP", slog.String("location", "udp://127.0.0.1:1234"))
+	out := buf.String()
+	for _, expected := range []string{
+		`level=WARN msg="queue full: value"`,
+		`level=ERROR msg="Error writing to UDP" location=udp://127.0.0.1:1234`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected %q in %q", expected, out)
+		}
+	}
+}
+
+func TestNewSlogLogger_Nil(t *testing.T) {
+	if l := NewSlogLogger(nil); l.log != slog.Default() {
+		t.Errorf("Expected the default slog logger")
+	}
+}
+
+func TestLogAttrs_Fallback(t *testing.T) {
+	rec := &recordingLogger{}
+
+	LogAttrs(rec, LevelInfo, "Flushing buffer", slog.Int("lines", 2), slog.Int("bytes", 10))
+	LogAttrs(rec, LevelWarn, "dropped payload")
+	Warnf(rec, "queue full: %d", 1)
+
+	expected := []string{
+		"info Flushing buffer lines=2 bytes=10",
+		"error dropped payload",
+		"error queue full: 1",
+	}
+	if !reflect.DeepEqual(rec.Messages(), expected) {
+		t.Errorf("Expected %v, got %v", expected, rec.Messages())
+	}
+	if !Enabled(rec, LevelDebug) {
+		t.Errorf("Expected every level to be enabled for a Logger without AttrLogger")
+	}
+}
+
+func TestLevelLogger_Attrs(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLevelLogger(NewSlogHandlerLogger(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})), LevelWarn)
+
+	if Enabled(l, LevelInfo) || !Enabled(l, LevelWarn) {
+		t.Errorf("Expected the level of the level logger to apply")
+	}
+	LogAttrs(l, LevelInfo, "re-dial unix socket")
+	LogAttrs(l, LevelWarn, "dropped payload", slog.Int("bytes", 10))
+	Warnf(l, "queue full")
+
+	out := buf.String()
+	if strings.Contains(out, "re-dial") || !strings.Contains(out, `level=WARN msg="dropped payload" bytes=10`) ||
+		!strings.Contains(out, `level=WARN msg="queue full"`) {
+		t.Errorf("Unexpected output %q", out)
+	}
+}
+
+func TestParseLevel_Warn(t *testing.T) {
+	for _, s := range []string{"warn", "WARNING"} {
+		if level, err := ParseLevel(s); err != nil || level != LevelWarn {
+			t.Errorf("Expected %q to parse as warn, got %v, %v", s, level, err)
+		}
+	}
+	if LevelWarn.String() != "warn" {
+		t.Errorf("Expected warn, got %s", LevelWarn)
+	}
+}
+
+// keyValueRecorder records the messages of a zap SugaredLogger style logger.
+type keyValueRecorder struct {
+	messages []string
+}
+
+func (r *keyValueRecorder) record(level string, msg string, kvs []interface{}) {
+	r.messages = append(r.messages, fmt.Sprint(level, " ", msg, kvs))
+}
+
+func (r *keyValueRecorder) Debugw(msg string, kvs ...interface{}) { r.record("debug", msg, kvs) }
+func (r *keyValueRecorder) Infow(msg string, kvs ...interface{})  { r.record("info", msg, kvs) }
+func (r *keyValueRecorder) Warnw(msg string, kvs ...interface{})  { r.record("warn", msg, kvs) }
+func (r *keyValueRecorder) Errorw(msg string, kvs ...interface{}) { r.record("error", msg, kvs) }
+
+func TestKeyValueLogger(t *testing.T) {
+	rec := &keyValueRecorder{}
+	l := NewKeyValueLogger(rec)
+
+	l.Infof("Initialize %s", "UdpWriter")
+	LogAttrs(l, LevelError, "Error writing to UDP", slog.String("location", "udp://127.0.0.1:1234"), slog.Int("bytes", 10))
+
+	expected := []string{
+		"info Initialize UdpWriter[]",
+		"error Error writing to UDP[location udp://127.0.0.1:1234 bytes 10]",
+	}
+	if !reflect.DeepEqual(rec.messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, rec.messages)
+	}
+}
+
+// fieldRecorder records the messages of a logrus style logger, and is its own entry type.
+type fieldRecorder struct {
+	fields   string
+	messages *[]string
+}
+
+func (r *fieldRecorder) WithField(key string, value interface{}) *fieldRecorder {
+	return &fieldRecorder{fields: fmt.Sprintf("%s %s=%v", r.fields, key, value), messages: r.messages}
+}
+
+func (r *fieldRecorder) record(level string, msg string) {
+	*r.messages = append(*r.messages, level+" "+msg+r.fields)
+}
+
+func (r *fieldRecorder) Debug(args ...interface{}) { r.record("debug", fmt.Sprint(args...)) }
+func (r *fieldRecorder) Info(args ...interface{})  { r.record("info", fmt.Sprint(args...)) }
+func (r *fieldRecorder) Warn(args ...interface{})  { r.record("warn", fmt.Sprint(args...)) }
+func (r *fieldRecorder) Error(args ...interface{}) { r.record("error", fmt.Sprint(args...)) }
+
+func (r *fieldRecorder) Debugf(format string, v ...interface{}) { r.Debug(fmt.Sprintf(format, v...)) }
+func (r *fieldRecorder) Infof(format string, v ...interface{})  { r.Info(fmt.Sprintf(format, v...)) }
+func (r *fieldRecorder) Warnf(format string, v ...interface{})  { r.Warn(fmt.Sprintf(format, v...)) }
+func (r *fieldRecorder) Errorf(format string, v ...interface{}) { r.Error(fmt.Sprintf(format, v...)) }
+
+func TestFieldLogger(t *testing.T) {
+	var messages []string
+	l := NewFieldLogger[*fieldRecorder](&fieldRecorder{messages: &messages})
+
+	Warnf(l, "queue full: %d", 1)
+	LogAttrs(l, LevelDebug, "Flushing buffer", slog.String("bufferSet", "front"), slog.Int("bytes", 10))
+	LogAttrs(l, LevelInfo, "re-dial unix socket")
+
+	expected := []string{
+		"warn queue full: 1",
+		"debug Flushing buffer bufferSet=front bytes=10",
+		"info re-dial unix socket",
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Level is the minimum severity of the messages written by a leveled Logger.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+}
+
+// slogLevel returns the equivalent slog.Level.
+func (l Level) slogLevel() slog.Level {
+	switch l {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// ParseLevel converts `debug`, `info`, `warn` (or `warning`) or `error`, in any case, to a Level.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid log level %q, expected debug, info, warn or error", s)
+	}
+}
+
+// levelLogger drops the messages below the minimum level, before they reach the wrapped Logger.
+type levelLogger struct {
+	log   Logger
+	level Level
+}
+
+// NewLevelLogger wraps a Logger, so that messages below the level are dropped without being formatted.
+func NewLevelLogger(log Logger, level Level) Logger {
+	return &levelLogger{log: log, level: level}
+}
+
+func (l *levelLogger) Debugf(format string, v ...interface{}) {
+	if l.level <= LevelDebug {
+		l.log.Debugf(format, v...)
+	}
+}
+
+func (l *levelLogger) Infof(format string, v ...interface{}) {
+	if l.level <= LevelInfo {
+		l.log.Infof(format, v...)
+	}
+}
+
+func (l *levelLogger) Warnf(format string, v ...interface{}) {
+	if l.level <= LevelWarn {
+		Warnf(l.log, format, v...)
+	}
+}
+
+func (l *levelLogger) Errorf(format string, v ...interface{}) {
+	l.log.Errorf(format, v...)
+}
+
+func (l *levelLogger) Enabled(level Level) bool {
+	return level >= l.level && Enabled(l.log, level)
+}
+
+func (l *levelLogger) LogAttrs(level Level, msg string, attrs ...slog.Attr) {
+	if level >= l.level {
+		LogAttrs(l.log, level, msg, attrs...)
+	}
+}
@@ -1,19 +1,100 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 )
 
 // Logger represents the shape of the logging dependency that the spectator
 // library expects.
+//
+// The loggers of most logging libraries satisfy it as is, such as the zap
+// SugaredLogger, and the logrus Logger and Entry. A Logger may also implement
+// WarnLogger and AttrLogger, which the library uses when they are available.
 type Logger interface {
 	Debugf(format string, v ...interface{})
 	Infof(format string, v ...interface{})
 	Errorf(format string, v ...interface{})
 }
 
-// DefaultLogger is a plain text stdout logger.
+// WarnLogger is implemented by loggers with a warning level. See Warnf.
+type WarnLogger interface {
+	Warnf(format string, v ...interface{})
+}
+
+// AttrLogger is implemented by loggers which accept structured attributes, and
+// which report whether a level is enabled, so that the messages of the levels
+// which are disabled are never built. See LogAttrs.
+type AttrLogger interface {
+	Enabled(level Level) bool
+	LogAttrs(level Level, msg string, attrs ...slog.Attr)
+}
+
+// Warnf logs a warning, with the Warnf method of the logger, if it implements
+// WarnLogger, or with Errorf otherwise.
+func Warnf(log Logger, format string, v ...interface{}) {
+	if wl, ok := log.(WarnLogger); ok {
+		wl.Warnf(format, v...)
+		return
+	}
+	log.Errorf(format, v...)
+}
+
+// Enabled reports whether the logger writes the messages of the level. It is
+// true for the loggers which do not implement AttrLogger. Check it before
+// building expensive attributes, on hot paths.
+func Enabled(log Logger, level Level) bool {
+	if al, ok := log.(AttrLogger); ok {
+		return al.Enabled(level)
+	}
+	return true
+}
+
+// LogAttrs logs a message with structured attributes. If the logger does not
+// implement AttrLogger, then the attributes are appended to the message, as
+// `key=value` pairs, and the message is logged with the method for the level.
+func LogAttrs(log Logger, level Level, msg string, attrs ...slog.Attr) {
+	if al, ok := log.(AttrLogger); ok {
+		if al.Enabled(level) {
+			al.LogAttrs(level, msg, attrs...)
+		}
+		return
+	}
+
+	text := formatAttrs(msg, attrs)
+	switch {
+	case level <= LevelDebug:
+		log.Debugf("%s", text)
+	case level == LevelInfo:
+		log.Infof("%s", text)
+	case level == LevelWarn:
+		Warnf(log, "%s", text)
+	default:
+		log.Errorf("%s", text)
+	}
+}
+
+// formatAttrs appends the attributes to the message, as `key=value` pairs.
+func formatAttrs(msg string, attrs []slog.Attr) string {
+	if len(attrs) == 0 {
+		return msg
+	}
+	var sb strings.Builder
+	sb.WriteString(msg)
+	for _, a := range attrs {
+		sb.WriteString(" ")
+		sb.WriteString(a.Key)
+		sb.WriteString("=")
+		sb.WriteString(a.Value.String())
+	}
+	return sb.String()
+}
+
+// DefaultLogger is a plain text stdout logger, which writes to the default
+// slog Logger. The messages of the levels which are disabled in the default
+// slog Logger are not formatted.
 type DefaultLogger struct {
 }
 
@@ -23,15 +104,40 @@ func NewDefaultLogger() *DefaultLogger {
 
 // Debugf is for debug level messages. Satisfies Logger interface.
 func (l *DefaultLogger) Debugf(format string, v ...interface{}) {
-	slog.Debug(fmt.Sprintf(format, v...))
+	logf(slog.Default(), LevelDebug, format, v)
 }
 
 // Infof is for info level messages. Satisfies Logger interface.
 func (l *DefaultLogger) Infof(format string, v ...interface{}) {
-	slog.Info(fmt.Sprintf(format, v...))
+	logf(slog.Default(), LevelInfo, format, v)
+}
+
+// Warnf is for warning level messages. Satisfies WarnLogger interface.
+func (l *DefaultLogger) Warnf(format string, v ...interface{}) {
+	logf(slog.Default(), LevelWarn, format, v)
 }
 
 // Errorf is for error level messages. Satisfies Logger interface.
 func (l *DefaultLogger) Errorf(format string, v ...interface{}) {
-	slog.Error(fmt.Sprintf(format, v...))
+	logf(slog.Default(), LevelError, format, v)
+}
+
+// Enabled reports whether the default slog Logger is enabled for the level.
+// Satisfies AttrLogger interface.
+func (l *DefaultLogger) Enabled(level Level) bool {
+	return slog.Default().Enabled(context.Background(), level.slogLevel())
+}
+
+// LogAttrs logs the message, with the attributes, to the default slog Logger.
+// Satisfies AttrLogger interface.
+func (l *DefaultLogger) LogAttrs(level Level, msg string, attrs ...slog.Attr) {
+	slog.Default().LogAttrs(context.Background(), level.slogLevel(), msg, attrs...)
+}
+
+// logf formats the message, only if the slog Logger is enabled for the level.
+func logf(log *slog.Logger, level Level, format string, v []interface{}) {
+	ctx := context.Background()
+	if log.Enabled(ctx, level.slogLevel()) {
+		log.Log(ctx, level.slogLevel(), fmt.Sprintf(format, v...))
+	}
 }
@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// rateLimitedLogger logs the first message of each kind, and then a summary of the repeated messages of
+// that kind, at most once per interval.
+type rateLimitedLogger struct {
+	log      Logger
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[rateLimitKey]*rateLimitEntry
+	stopped bool
+}
+
+// rateLimitKey identifies a kind of message, by level and format string, or message for the structured
+// messages, so that the messages which only differ by their arguments or attributes, such as the error,
+// are counted together.
+type rateLimitKey struct {
+	level      Level
+	format     string
+	structured bool
+}
+
+type rateLimitEntry struct {
+	lastLogged time.Time
+	suppressed int
+	// args are the arguments of the last suppressed message, which are formatted by the summary
+	args []interface{}
+	// attrs are the attributes of the last suppressed structured message
+	attrs []slog.Attr
+	timer *time.Timer
+}
+
+// StopLogger is implemented by loggers which run timers, and which must be stopped once they are no longer
+// used.
+type StopLogger interface {
+	Stop()
+}
+
+// NewRateLimitedLogger wraps a Logger, so that a warning or an error which repeats, such as a write error
+// while spectatord is down, does not flood the logs. Messages with the same level and format string are
+// logged at most once per interval: the first one is logged, and the following ones are counted, and
+// reported in a summary with the last message, at the end of the interval. Structured messages, logged
+// with LogAttrs, are counted by level and message, and the summary adds the `suppressed` and `interval`
+// attributes. Debug and info messages, such as lifecycle events, are not rate limited. If the interval is
+// not positive, the Logger is returned as is.
+//
+// The returned Logger implements StopLogger, to stop the timers of the summaries.
+func NewRateLimitedLogger(log Logger, interval time.Duration) Logger {
+	if interval <= 0 {
+		return log
+	}
+	return &rateLimitedLogger{
+		log:      log,
+		interval: interval,
+		now:      time.Now,
+		entries:  make(map[rateLimitKey]*rateLimitEntry),
+	}
+}
+
+func (l *rateLimitedLogger) Debugf(format string, v ...interface{}) {
+	l.log.Debugf(format, v...)
+}
+
+func (l *rateLimitedLogger) Infof(format string, v ...interface{}) {
+	l.log.Infof(format, v...)
+}
+
+func (l *rateLimitedLogger) Warnf(format string, v ...interface{}) {
+	if l.allow(rateLimitKey{level: LevelWarn, format: format}, v, nil) {
+		Warnf(l.log, format, v...)
+	}
+}
+
+func (l *rateLimitedLogger) Errorf(format string, v ...interface{}) {
+	if l.allow(rateLimitKey{level: LevelError, format: format}, v, nil) {
+		l.log.Errorf(format, v...)
+	}
+}
+
+func (l *rateLimitedLogger) Enabled(level Level) bool {
+	return Enabled(l.log, level)
+}
+
+func (l *rateLimitedLogger) LogAttrs(level Level, msg string, attrs ...slog.Attr) {
+	if level < LevelWarn || l.allow(rateLimitKey{level: level, format: msg, structured: true}, nil, attrs) {
+		LogAttrs(l.log, level, msg, attrs...)
+	}
+}
+
+// allow reports whether the message is logged now, or counts it for the summary.
+func (l *rateLimitedLogger) allow(key rateLimitKey, v []interface{}, attrs []slog.Attr) bool {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stopped {
+		return true
+	}
+	e, ok := l.entries[key]
+	if !ok {
+		l.entries[key] = &rateLimitEntry{lastLogged: now}
+		return true
+	}
+	if e.suppressed == 0 && now.Sub(e.lastLogged) >= l.interval {
+		e.lastLogged = now
+		return true
+	}
+
+	e.suppressed++
+	e.args = v
+	e.attrs = attrs
+	if e.timer == nil {
+		e.timer = time.AfterFunc(e.lastLogged.Add(l.interval).Sub(now), func() {
+			l.summarize(key)
+		})
+	}
+	return false
+}
+
+// summarize logs the last suppressed message of the kind, with the number of messages suppressed.
+func (l *rateLimitedLogger) summarize(key rateLimitKey) {
+	l.mu.Lock()
+	e := l.entries[key]
+	e.timer = nil
+	suppressed, args, attrs := e.suppressed, e.args, e.attrs
+	if suppressed == 0 {
+		l.mu.Unlock()
+		return
+	}
+	e.suppressed = 0
+	e.args = nil
+	e.attrs = nil
+	e.lastLogged = l.now()
+	l.mu.Unlock()
+
+	if key.structured {
+		attrs = append(append([]slog.Attr(nil), attrs...),
+			slog.Int("suppressed", suppressed), slog.Duration("interval", l.interval))
+		LogAttrs(l.log, key.level, key.format, attrs...)
+		return
+	}
+
+	format := key.format + " (%d similar messages suppressed in the last %s)"
+	args = append(append([]interface{}(nil), args...), suppressed, l.interval)
+	switch key.level {
+	case LevelWarn:
+		Warnf(l.log, format, args...)
+	default:
+		l.log.Errorf(format, args...)
+	}
+}
+
+// Stop stops the timers, and logs the summaries of the messages suppressed so far. The messages logged
+// afterwards are not rate limited. It is safe to call more than once.
+func (l *rateLimitedLogger) Stop() {
+	l.mu.Lock()
+	l.stopped = true
+	var pending []rateLimitKey
+	for key, e := range l.entries {
+		if e.timer != nil {
+			e.timer.Stop()
+		}
+		if e.suppressed > 0 {
+			pending = append(pending, key)
+		}
+	}
+	l.mu.Unlock()
+
+	for _, key := range pending {
+		l.summarize(key)
+	}
+}
@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingLogger records the formatted messages, prefixed by their level.
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) record(level string, format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, level+" "+fmt.Sprintf(format, v...))
+}
+
+func (l *recordingLogger) Debugf(format string, v ...interface{}) {
+	l.record("debug", format, v...)
+}
+
+func (l *recordingLogger) Infof(format string, v ...interface{}) {
+	l.record("info", format, v...)
+}
+
+func (l *recordingLogger) Errorf(format string, v ...interface{}) {
+	l.record("error", format, v...)
+}
+
+func (l *recordingLogger) Messages() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.messages...)
+}
+
+// newTestRateLimitedLogger creates a logger with a clock controlled by the test.
+func newTestRateLimitedLogger() (*rateLimitedLogger, *recordingLogger, *time.Time) {
+	rec := &recordingLogger{}
+	l := NewRateLimitedLogger(rec, time.Hour).(*rateLimitedLogger)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	return l, rec, &now
+}
+
+func TestRateLimitedLogger_Summary(t *testing.T) {
+	l, rec, _ := newTestRateLimitedLogger()
+
+	for i := 1; i <= 5; i++ {
+		l.Errorf("Error writing to UDP: %s", fmt.Sprintf("error %d", i))
+	}
+	l.Errorf("failed to dial unix socket: %v", "refused")
+
+	expected := []string{
+		"error Error writing to UDP: error 1",
+		"error failed to dial unix socket: refused",
+	}
+	if fmt.Sprint(rec.Messages()) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, rec.Messages())
+	}
+
+	l.summarize(rateLimitKey{level: LevelError, format: "Error writing to UDP: %s"})
+	summary := "error Error writing to UDP: error 5 (4 similar messages suppressed in the last 1h0m0s)"
+	if messages := rec.Messages(); len(messages) != 3 || messages[2] != summary {
+		t.Errorf("Expected the summary %q, got %v", summary, messages)
+	}
+}
+
+func TestRateLimitedLogger_Attrs(t *testing.T) {
+	l, rec, _ := newTestRateLimitedLogger()
+
+	for i := 1; i <= 3; i++ {
+		l.LogAttrs(LevelWarn, "Error writing to UDP", slog.String("error", fmt.Sprintf("error %d", i)))
+	}
+
+	expected := []string{"error Error writing to UDP error=error 1"}
+	if fmt.Sprint(rec.Messages()) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, rec.Messages())
+	}
+
+	l.summarize(rateLimitKey{level: LevelWarn, format: "Error writing to UDP", structured: true})
+	summary := "error Error writing to UDP error=error 3 suppressed=2 interval=1h0m0s"
+	if messages := rec.Messages(); len(messages) != 2 || messages[1] != summary {
+		t.Errorf("Expected the summary %q, got %v", summary, messages)
+	}
+}
+
+func TestRateLimitedLogger_AfterInterval(t *testing.T) {
+	l, rec, now := newTestRateLimitedLogger()
+
+	l.Errorf("re-dial unix socket")
+	*now = now.Add(time.Hour)
+	l.Errorf("re-dial unix socket")
+
+	if len(rec.Messages()) != 2 {
+		t.Errorf("Expected the message to be logged again after the interval, got %v", rec.Messages())
+	}
+}
+
+func TestRateLimitedLogger_Debug(t *testing.T) {
+	l, rec, _ := newTestRateLimitedLogger()
+
+	l.Debugf("Sending line: %s", "c:a:1")
+	l.Debugf("Sending line: %s", "c:a:1")
+
+	if len(rec.Messages()) != 2 {
+		t.Errorf("Expected debug messages not to be rate limited, got %v", rec.Messages())
+	}
+}
+
+func TestRateLimitedLogger_Info(t *testing.T) {
+	l, rec, _ := newTestRateLimitedLogger()
+
+	l.Infof("Close Registry Writer")
+	l.Infof("Close Registry Writer")
+	l.LogAttrs(LevelInfo, "Reconfigure Registry")
+	l.LogAttrs(LevelInfo, "Reconfigure Registry")
+
+	if len(rec.Messages()) != 4 {
+		t.Errorf("Expected info messages not to be rate limited, got %v", rec.Messages())
+	}
+}
+
+func TestRateLimitedLogger_Stop(t *testing.T) {
+	l, rec, _ := newTestRateLimitedLogger()
+
+	l.Errorf("failed: %d", 1)
+	l.Errorf("failed: %d", 2)
+	l.Stop()
+
+	messages := rec.Messages()
+	if len(messages) != 2 || messages[1] != "error failed: 2 (1 similar messages suppressed in the last 1h0m0s)" {
+		t.Errorf("Expected the pending summary on Stop, got %v", messages)
+	}
+	if e := l.entries[rateLimitKey{level: LevelError, format: "failed: %d"}]; e.timer != nil {
+		t.Errorf("Expected the timer to be stopped")
+	}
+
+	l.Errorf("failed: %d", 3)
+	l.Stop()
+	if len(rec.Messages()) != 3 {
+		t.Errorf("Expected messages not to be rate limited after Stop, got %v", rec.Messages())
+	}
+}
+
+func TestRateLimitedLogger_Timer(t *testing.T) {
+	rec := &recordingLogger{}
+	l := NewRateLimitedLogger(rec, 10*time.Millisecond)
+
+	l.Errorf("failed: %d", 1)
+	l.Errorf("failed: %d", 2)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(rec.Messages()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	messages := rec.Messages()
+	if len(messages) != 2 || messages[1] != "error failed: 2 (1 similar messages suppressed in the last 10ms)" {
+		t.Errorf("Expected a summary at the end of the interval, got %v", messages)
+	}
+}
+
+func TestNewRateLimitedLogger_Disabled(t *testing.T) {
+	rec := &recordingLogger{}
+	if l := NewRateLimitedLogger(rec, 0); l != Logger(rec) {
+		t.Errorf("Expected the logger to be returned as is, got %T", l)
+	}
+}
@@ -7,10 +7,18 @@ import (
 	"sync"
 )
 
-// Id represents a meter's identifying information and dimensions (tags).
+// Tag is a dimension of a meter Id.
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// Id represents a meter's identifying information and dimensions (tags). An *Id is immutable, and its
+// tags are stored in a slice sorted by key, so that the methods which derive a new *Id only copy the
+// slice, and the spectatord form of the *Id is stable.
 type Id struct {
 	name string
-	tags map[string]string
+	tags []Tag
 	// keyOnce protects access to key, allowing it to be computed on demand
 	// without racing other readers.
 	keyOnce sync.Once
@@ -40,39 +48,14 @@ func (id *Id) MapKey() string {
 		buf.Reset()
 		defer builderPool.Put(buf)
 
-		const errKey = "ERR"
-		id.key = func() string {
-			_, err := buf.WriteString(id.name)
-			if err != nil {
-				return errKey
-			}
-			keys := make([]string, 0, len(id.tags))
-			for k := range id.tags {
-				keys = append(keys, k)
-			}
-			sort.Strings(keys)
-
-			for _, k := range keys {
-				v := id.tags[k]
-				_, err = buf.WriteRune('|')
-				if err != nil {
-					return errKey
-				}
-				_, err = buf.WriteString(k)
-				if err != nil {
-					return errKey
-				}
-				_, err = buf.WriteRune('|')
-				if err != nil {
-					return errKey
-				}
-				_, err = buf.WriteString(v)
-				if err != nil {
-					return errKey
-				}
-			}
-			return buf.String()
-		}()
+		buf.WriteString(id.name)
+		for _, t := range id.tags {
+			buf.WriteRune('|')
+			buf.WriteString(t.Key)
+			buf.WriteRune('|')
+			buf.WriteString(t.Value)
+		}
+		id.key = intern(buf.String())
 	})
 	return id.key
 }
@@ -80,35 +63,66 @@ func (id *Id) MapKey() string {
 // NewId generates a new *Id from the metric name, and the tags you want to
 // include on your metric.
 func NewId(name string, tags map[string]string) *Id {
-	myTags := make(map[string]string)
+	tagList := make([]Tag, 0, len(tags))
 	for k, v := range tags {
-		myTags[k] = v
+		tagList = append(tagList, Tag{Key: k, Value: v})
 	}
+	sort.Slice(tagList, func(i, j int) bool { return tagList[i].Key < tagList[j].Key })
 
-	spectatorId := toSpectatorId(name, tags)
+	return newSortedId(name, tagList)
+}
 
+// NewIdFromTags generates a new *Id from the metric name, and a list of tags, which is copied. When a
+// key is repeated, the last value is used.
+func NewIdFromTags(name string, tags ...Tag) *Id {
+	return newSortedId(name, sortTags(append([]Tag(nil), tags...)))
+}
+
+// newSortedId creates an *Id, which takes ownership of the tags, sorted by key, with unique keys.
+func newSortedId(name string, tags []Tag) *Id {
 	return &Id{
 		name:         name,
-		tags:         myTags,
-		spectatordId: spectatorId,
+		tags:         tags,
+		spectatordId: intern(toSpectatorId(name, tags)),
 	}
 }
 
-// WithTag creates a deep copy of the *Id, adding the requested tag to the
-// internal collection.
-func (id *Id) WithTag(key string, value string) *Id {
-	newTags := make(map[string]string)
+// sortTags sorts the tags by key in place, and removes the repeated keys, keeping the last value.
+func sortTags(tags []Tag) []Tag {
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Key < tags[j].Key })
+
+	unique := tags[:0]
+	for i, t := range tags {
+		if i+1 < len(tags) && tags[i+1].Key == t.Key {
+			continue
+		}
+		unique = append(unique, t)
+	}
+	return unique
+}
 
-	for k, v := range id.tags {
-		newTags[k] = v
+// WithTag returns a copy of the *Id, with the tag added, or replaced if the key is already present. If
+// the *Id already has the tag, then it is returned as is.
+func (id *Id) WithTag(key string, value string) *Id {
+	i := sort.Search(len(id.tags), func(i int) bool { return id.tags[i].Key >= key })
+	if i < len(id.tags) && id.tags[i].Key == key {
+		if id.tags[i].Value == value {
+			return id
+		}
+		newTags := append([]Tag(nil), id.tags...)
+		newTags[i].Value = value
+		return newSortedId(id.name, newTags)
 	}
-	newTags[key] = value
 
-	return NewId(id.name, newTags)
+	newTags := make([]Tag, 0, len(id.tags)+1)
+	newTags = append(newTags, id.tags[:i]...)
+	newTags = append(newTags, Tag{Key: key, Value: value})
+	newTags = append(newTags, id.tags[i:]...)
+	return newSortedId(id.name, newTags)
 }
 
 func (id *Id) String() string {
-	return fmt.Sprintf("Id{name=%s,tags=%v}", id.name, id.tags)
+	return fmt.Sprintf("Id{name=%s,tags=%v}", id.name, id.Tags())
 }
 
 // Name exposes the internal metric name field.
@@ -116,10 +130,34 @@ func (id *Id) Name() string {
 	return id.name
 }
 
-// Tags directly exposes the internal tags map. This is not a copy of the map,
-// so any modifications to it will be observed by the *Id.
+// Tags returns a copy of the tags, as a map. Prefer Tag, or TagList, which do not build a map.
 func (id *Id) Tags() map[string]string {
-	return id.tags
+	tags := make(map[string]string, len(id.tags))
+	for _, t := range id.tags {
+		tags[t.Key] = t.Value
+	}
+	return tags
+}
+
+// TagList returns a copy of the tags, sorted by key.
+func (id *Id) TagList() []Tag {
+	return append([]Tag(nil), id.tags...)
+}
+
+// Tag returns the value of the tag with the key, and reports whether the *Id has the tag.
+func (id *Id) Tag(key string) (string, bool) {
+	i := sort.Search(len(id.tags), func(i int) bool { return id.tags[i].Key >= key })
+	if i < len(id.tags) && id.tags[i].Key == key {
+		return id.tags[i].Value, true
+	}
+	return "", false
+}
+
+// SpectatordId returns the *Id formatted for the spectatord line protocol, with invalid characters
+// in the name, tag keys and tag values replaced by underscores. The tags are sorted by key. See ParseId
+// for the exact rules.
+func (id *Id) SpectatordId() string {
+	return id.spectatordId
 }
 
 // WithTags takes a map of tags, and returns a deep copy of *Id with the new
@@ -130,33 +168,67 @@ func (id *Id) WithTags(tags map[string]string) *Id {
 		return id
 	}
 
-	newTags := make(map[string]string)
+	newTags := make([]Tag, 0, len(id.tags)+len(tags))
+	newTags = append(newTags, id.tags...)
+	for k, v := range tags {
+		newTags = append(newTags, Tag{Key: k, Value: v})
+	}
+	return newSortedId(id.name, sortTags(newTags))
+}
 
-	for k, v := range id.tags {
-		newTags[k] = v
+// Validate returns an error if the name is empty, if a tag key or value is empty, or if the name, tag
+// keys or tag values contain characters that spectatord does not accept. Such characters are replaced
+// with underscores, when the *Id is formatted for the line protocol.
+func (id *Id) Validate() error {
+	if id.name == "" {
+		return fmt.Errorf("meter name may not be empty")
+	}
+	if err := validateCharacters("name", id.name); err != nil {
+		return err
 	}
 
-	for k, v := range tags {
-		newTags[k] = v
+	for _, t := range id.tags {
+		k, v := t.Key, t.Value
+		if k == "" || v == "" {
+			return fmt.Errorf("meter %s has an empty tag key or value: %q=%q", id.name, k, v)
+		}
+		if err := validateCharacters("tag key", k); err != nil {
+			return fmt.Errorf("meter %s: %w", id.name, err)
+		}
+		if err := validateCharacters("tag value", v); err != nil {
+			return fmt.Errorf("meter %s: %w", id.name, err)
+		}
 	}
-	return NewId(id.name, newTags)
+
+	return nil
 }
 
-func toSpectatorId(name string, tags map[string]string) string {
+func validateCharacters(field string, input string) error {
+	for i, r := range input {
+		if !isValidCharacter(r) {
+			return fmt.Errorf("%s %q has invalid character %q at offset %d", field, input, r, i)
+		}
+	}
+	return nil
+}
+
+func toSpectatorId(name string, tags []Tag) string {
 	var sb strings.Builder
 	writeSanitized(&sb, name)
 
 	// Append sanitized keys and values.
-	for k, v := range tags {
+	for _, t := range tags {
 		sb.WriteString(",")
-		writeSanitized(&sb, k)
+		writeSanitized(&sb, t.Key)
 		sb.WriteString("=")
-		writeSanitized(&sb, v)
+		writeSanitized(&sb, t.Value)
 	}
 
 	return sb.String()
 }
 
+// writeSanitized writes the input, with each rune that is not a valid character replaced by a single
+// underscore. A multi-byte UTF-8 sequence is one rune, and an invalid byte is one rune.
 func writeSanitized(sb *strings.Builder, input string) {
 	for _, r := range input {
 		if !isValidCharacter(r) {
@@ -0,0 +1,37 @@
+package meter
+
+// IdBuilder constructs an *Id incrementally, without building a map. The zero value is not usable, so
+// create it with NewIdBuilder. An IdBuilder is not safe for concurrent use.
+//
+//	id := meter.NewIdBuilder("server.requests").
+//		WithTag("method", method).
+//		WithTag("status", status).
+//		Build()
+type IdBuilder struct {
+	name string
+	tags []Tag
+}
+
+// NewIdBuilder creates a builder for an *Id with the name.
+func NewIdBuilder(name string) *IdBuilder {
+	return &IdBuilder{name: name}
+}
+
+// WithTag adds a tag. When a key is repeated, the last value is used.
+func (b *IdBuilder) WithTag(key string, value string) *IdBuilder {
+	b.tags = append(b.tags, Tag{Key: key, Value: value})
+	return b
+}
+
+// WithTags adds the tags of the map.
+func (b *IdBuilder) WithTags(tags map[string]string) *IdBuilder {
+	for k, v := range tags {
+		b.tags = append(b.tags, Tag{Key: k, Value: v})
+	}
+	return b
+}
+
+// Build creates the *Id. The builder may be used again afterwards, and keeps its tags.
+func (b *IdBuilder) Build() *Id {
+	return NewIdFromTags(b.name, b.tags...)
+}
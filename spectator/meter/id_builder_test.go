@@ -0,0 +1,31 @@
+package meter
+
+import "testing"
+
+func TestIdBuilder(t *testing.T) {
+	builder := NewIdBuilder("server.requests").
+		WithTag("status", "200").
+		WithTags(map[string]string{"method": "GET"}).
+		WithTag("status", "500")
+
+	id := builder.Build()
+	if id.SpectatordId() != "server.requests,method=GET,status=500" {
+		t.Errorf("Expected server.requests,method=GET,status=500, got %s", id.SpectatordId())
+	}
+
+	// the builder may be reused, without changing the Ids already built
+	other := builder.WithTag("region", "us-east-1").Build()
+	if other.SpectatordId() != "server.requests,method=GET,region=us-east-1,status=500" {
+		t.Errorf("Unexpected Id %s", other.SpectatordId())
+	}
+	if id.SpectatordId() != "server.requests,method=GET,status=500" {
+		t.Errorf("Expected the first Id to be unchanged, got %s", id.SpectatordId())
+	}
+}
+
+func TestIdBuilder_NoTags(t *testing.T) {
+	id := NewIdBuilder("foo").Build()
+	if id.SpectatordId() != "foo" || len(id.TagList()) != 0 {
+		t.Errorf("Expected foo without tags, got %s", id.SpectatordId())
+	}
+}
@@ -0,0 +1,73 @@
+package meter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseId parses an *Id from the spectatord form, `name,key1=value1,key2=value2`, as returned by
+// SpectatordId. It is the inverse of SpectatordId, for Ids with valid characters.
+//
+// The spectatord form has no escape sequences. The valid characters are `a-z`, `A-Z`, `0-9`, and the
+// symbols `-`, `.`, `_`, `~` and `^`. When an *Id is formatted, each other character in the name, tag
+// keys and tag values is replaced by a single `_`, including the separators `,`, `=` and `:`, spaces,
+// and multi-byte UTF-8 characters, so the original characters cannot be recovered. For example, the
+// name `foo bar` and the tag `a/b=é` are formatted as `foo_bar,a_b=_`.
+//
+// ParseId does not replace invalid characters. Instead, it returns an error with the first invalid
+// character and its byte offset in s. It also returns an error for an empty name, an empty tag key or
+// value, a tag without `=`, and a repeated tag key. The tags may be in any order.
+func ParseId(s string) (*Id, error) {
+	name, rest, hasTags := strings.Cut(s, ",")
+	if name == "" {
+		return nil, fmt.Errorf("invalid id %q: empty name", s)
+	}
+	if err := checkCharacters(s, name, 0); err != nil {
+		return nil, err
+	}
+
+	var tags []Tag
+	offset := len(name) + 1
+	for hasTags {
+		var pair string
+		pair, rest, hasTags = strings.Cut(rest, ",")
+
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid id %q: tag %q at offset %d has no '='", s, pair, offset)
+		}
+		if key == "" || value == "" {
+			return nil, fmt.Errorf("invalid id %q: tag %q at offset %d has an empty key or value", s, pair, offset)
+		}
+		if err := checkCharacters(s, key, offset); err != nil {
+			return nil, err
+		}
+		if err := checkCharacters(s, value, offset+len(key)+1); err != nil {
+			return nil, err
+		}
+		for _, t := range tags {
+			if t.Key == key {
+				return nil, fmt.Errorf("invalid id %q: repeated tag key %q at offset %d", s, key, offset)
+			}
+		}
+
+		tags = append(tags, Tag{Key: key, Value: value})
+		offset += len(pair) + 1
+	}
+
+	return newSortedId(name, sortTags(tags)), nil
+}
+
+// checkCharacters reports the first invalid character of the field, which starts at the offset in s.
+func checkCharacters(s string, field string, offset int) error {
+	for i, r := range field {
+		if !isValidCharacter(r) {
+			if r == utf8.RuneError {
+				return fmt.Errorf("invalid id %q: invalid UTF-8 at offset %d", s, offset+i)
+			}
+			return fmt.Errorf("invalid id %q: invalid character %q at offset %d", s, r, offset+i)
+		}
+	}
+	return nil
+}
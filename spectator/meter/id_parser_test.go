@@ -0,0 +1,70 @@
+package meter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseId(t *testing.T) {
+	id, err := ParseId("server.requests,status=200,method=GET")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if id.Name() != "server.requests" {
+		t.Errorf("Expected server.requests, got %s", id.Name())
+	}
+	if v, _ := id.Tag("method"); v != "GET" {
+		t.Errorf("Expected method=GET, got %s", v)
+	}
+	if id.SpectatordId() != "server.requests,method=GET,status=200" {
+		t.Errorf("Expected sorted tags, got %s", id.SpectatordId())
+	}
+}
+
+func TestParseId_RoundTrip(t *testing.T) {
+	ids := []*Id{
+		NewId("foo", nil),
+		NewId("foo.bar", map[string]string{"a-b": "c_d~^", "x": "1.5"}),
+		NewId("foo bar", map[string]string{"a/b": "é"}),
+	}
+
+	for _, id := range ids {
+		parsed, err := ParseId(id.SpectatordId())
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", id.SpectatordId(), err)
+			continue
+		}
+		if parsed.SpectatordId() != id.SpectatordId() {
+			t.Errorf("Expected %s, got %s", id.SpectatordId(), parsed.SpectatordId())
+		}
+	}
+}
+
+func TestParseId_Errors(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"", "empty name"},
+		{",a=b", "empty name"},
+		{"foo bar", "invalid character ' ' at offset 3"},
+		{"foo,a=b c", "invalid character ' ' at offset 7"},
+		{"foo,a:b=c", "invalid character ':' at offset 5"},
+		{"foo,é=c", "invalid character 'é' at offset 4"},
+		{"foo,a=\xff", "invalid UTF-8 at offset 6"},
+		{"foo,a", "has no '='"},
+		{"foo,a=b=c", "invalid character '=' at offset 7"},
+		{"foo,a=", "empty key or value"},
+		{"foo,=b", "empty key or value"},
+		{"foo,", "has no '='"},
+		{"foo,a=b,a=c", "repeated tag key \"a\" at offset 8"},
+	}
+
+	for _, tc := range testCases {
+		_, err := ParseId(tc.input)
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("Expected error containing %q for %q, got %v", tc.expected, tc.input, err)
+		}
+	}
+}
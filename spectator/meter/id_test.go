@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"unsafe"
 )
 
 func TestId_mapKey(t *testing.T) {
@@ -107,13 +108,12 @@ func TestToSpectatorId(t *testing.T) {
 		"tag2": "value2",
 	}
 
-	// The order of the tags is not guaranteed
-	expected1 := "test,tag1=value1,tag2=value2"
-	expected2 := "test,tag2=value2,tag1=value1"
-	result := toSpectatorId(name, tags)
+	// The tags are sorted by key
+	expected := "test,tag1=value1,tag2=value2"
+	result := NewId(name, tags).SpectatordId()
 
-	if result != expected1 && result != expected2 {
-		t.Errorf("Expected '%s' or '%s', got '%s'", expected1, expected2, result)
+	if result != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, result)
 	}
 }
 
@@ -122,7 +122,7 @@ func TestToSpectatorId_EmptyTags(t *testing.T) {
 	tags := map[string]string{}
 
 	expected := "test"
-	result := toSpectatorId(name, tags)
+	result := NewId(name, tags).SpectatordId()
 
 	if result != expected {
 		t.Errorf("Expected '%s', got '%s'", expected, result)
@@ -136,12 +136,11 @@ func TestToSpectatorId_InvalidTags(t *testing.T) {
 		"tag2,;=": "value2,;=",
 	}
 
-	expected1 := "test______^____-_~______________.___foo,tag1___=value1___,tag2___=value2___"
-	expected2 := "test______^____-_~______________.___foo,tag2___=value2___,tag1___=value1___"
-	result := toSpectatorId(name, tags)
+	expected := "test______^____-_~______________.___foo,tag1___=value1___,tag2___=value2___"
+	result := NewId(name, tags).SpectatordId()
 
-	if result != expected1 && result != expected2 {
-		t.Errorf("Expected '%s' or '%s', got '%s'", expected1, expected2, result)
+	if result != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, result)
 	}
 }
 
@@ -187,8 +186,119 @@ func BenchmarkToSpectatorId(b *testing.B) {
 }
 
 func BenchmarkToSpectatorIdBuilder(b *testing.B) {
+	benchTagList := NewId(benchName, benchTags).TagList()
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		_ = toSpectatorId(benchName, benchTagList)
+	}
+}
+
+func TestId_SpectatordId(t *testing.T) {
+	id := NewId("foo bar", map[string]string{"a/b": "c"})
+	expected := "foo_bar,a_b=c"
+	if id.SpectatordId() != expected {
+		t.Errorf("Expected %s, got %s", expected, id.SpectatordId())
+	}
+}
+
+func TestId_Validate(t *testing.T) {
+	testCases := []struct {
+		id    *Id
+		valid bool
+	}{
+		{NewId("foo.bar", map[string]string{"a-b": "c_d~^"}), true},
+		{NewId("", nil), false},
+		{NewId("foo bar", nil), false},
+		{NewId("foo", map[string]string{"a:b": "c"}), false},
+		{NewId("foo", map[string]string{"a": "c,d"}), false},
+		{NewId("foo", map[string]string{"a": ""}), false},
+	}
+
+	for _, tc := range testCases {
+		err := tc.id.Validate()
+		if (err == nil) != tc.valid {
+			t.Errorf("Expected valid=%v for %v, got error %v", tc.valid, tc.id, err)
+		}
+	}
+}
+
+func TestId_TagsReturnsCopy(t *testing.T) {
+	id := NewId("foo", map[string]string{"a": "b"})
+
+	id.Tags()["a"] = "zzz"
+	id.TagList()[0].Value = "zzz"
+
+	if v, _ := id.Tag("a"); v != "b" {
+		t.Errorf("Expected the tags of the Id to be immutable, got a=%s", v)
+	}
+}
+
+func TestId_Tag(t *testing.T) {
+	id := NewId("foo", map[string]string{"a": "1", "c": "3"})
+
+	if v, ok := id.Tag("c"); !ok || v != "3" {
+		t.Errorf("Expected c=3, got %s, %v", v, ok)
+	}
+	if _, ok := id.Tag("b"); ok {
+		t.Errorf("Expected tag b to be missing")
+	}
+}
+
+func TestId_WithTag(t *testing.T) {
+	id := NewId("foo", map[string]string{"a": "1", "c": "3"})
+
+	testCases := []struct {
+		key, value string
+		expected   string
+	}{
+		{"b", "2", "foo,a=1,b=2,c=3"},
+		{"0", "0", "foo,0=0,a=1,c=3"},
+		{"d", "4", "foo,a=1,c=3,d=4"},
+		{"c", "4", "foo,a=1,c=4"},
+	}
+
+	for _, tc := range testCases {
+		if actual := id.WithTag(tc.key, tc.value).SpectatordId(); actual != tc.expected {
+			t.Errorf("Expected %s, got %s", tc.expected, actual)
+		}
+	}
+
+	if id.WithTag("a", "1") != id {
+		t.Errorf("Expected the same Id when the tag is unchanged")
+	}
+	if id.SpectatordId() != "foo,a=1,c=3" {
+		t.Errorf("Expected the original Id to be unchanged, got %s", id.SpectatordId())
+	}
+}
+
+func TestNewIdFromTags(t *testing.T) {
+	tags := []Tag{{"b", "1"}, {"a", "1"}, {"b", "2"}}
+	id := NewIdFromTags("foo", tags...)
+
+	if id.SpectatordId() != "foo,a=1,b=2" {
+		t.Errorf("Expected foo,a=1,b=2, got %s", id.SpectatordId())
+	}
+	if tags[0] != (Tag{"b", "1"}) {
+		t.Errorf("Expected the input tags to be unchanged, got %v", tags)
+	}
+}
+
+func TestId_InternsStrings(t *testing.T) {
+	id1 := NewId("interned", map[string]string{"a": "b"})
+	id2 := NewIdFromTags("interned", Tag{"a", "b"})
+
+	if unsafe.StringData(id1.SpectatordId()) != unsafe.StringData(id2.SpectatordId()) {
+		t.Errorf("Expected equal Ids to share the spectatordId string")
+	}
+	if unsafe.StringData(id1.MapKey()) != unsafe.StringData(id2.MapKey()) {
+		t.Errorf("Expected equal Ids to share the MapKey string")
+	}
+}
+
+func BenchmarkId_WithTag(b *testing.B) {
+	id := NewId(benchName, benchTags)
 	b.ReportAllocs()
 	for n := 0; n < b.N; n++ {
-		_ = toSpectatorId(benchName, benchTags)
+		_ = id.WithTag("status", "200")
 	}
 }
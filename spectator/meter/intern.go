@@ -0,0 +1,31 @@
+package meter
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// maxInterned bounds the number of interned strings, so that high-cardinality Ids do not grow the table
+// forever. Once it is full, strings are returned as is.
+const maxInterned = 100_000
+
+var (
+	internTable sync.Map
+	internCount atomic.Int64
+)
+
+// intern returns a canonical copy of the string, so that the Ids which are created repeatedly, such as in
+// a loop, share the memory of their spectatordId and MapKey.
+func intern(s string) string {
+	if v, ok := internTable.Load(s); ok {
+		return v.(string)
+	}
+	if internCount.Load() >= maxInterned {
+		return s
+	}
+	if v, loaded := internTable.LoadOrStore(s, s); loaded {
+		return v.(string)
+	}
+	internCount.Add(1)
+	return s
+}
@@ -0,0 +1,177 @@
+package meter
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+// The bucket layout of the percentile meters, which is the same as the PercentileBuckets of Java
+// Spectator, so that the estimates computed in-process match the ones computed by the backend.
+//
+// The buckets are the values 1, 2 and 3, followed, for each power of 4, by steps of a third of that power
+// up to the next power of 4, and a last bucket for math.MaxInt64. This bounds the relative error of an
+// estimate to about 25%, with 276 buckets for the whole int64 range.
+const percentileBucketsLength = 276
+
+var (
+	percentileBucketValues []int64
+	// powerOf4Index holds, for each power of 4, the index of its bucket, followed by the index of the
+	// last bucket
+	powerOf4Index []int
+)
+
+func init() {
+	percentileBucketValues = []int64{1, 2, 3}
+	powerOf4Index = []int{0}
+	for exp := 2; exp < 64; exp += 2 {
+		current := int64(1) << exp
+		delta := current / 3
+		next := (current << 2) - delta
+		powerOf4Index = append(powerOf4Index, len(percentileBucketValues))
+		for current < next {
+			percentileBucketValues = append(percentileBucketValues, current)
+			current += delta
+		}
+	}
+	powerOf4Index = append(powerOf4Index, len(percentileBucketValues))
+	percentileBucketValues = append(percentileBucketValues, math.MaxInt64)
+	if len(percentileBucketValues) != percentileBucketsLength {
+		panic("invalid percentile bucket layout")
+	}
+}
+
+// PercentileBucketsLength returns the number of percentile buckets.
+func PercentileBucketsLength() int {
+	return len(percentileBucketValues)
+}
+
+// PercentileBucketValue returns the upper bound of the bucket at index i.
+func PercentileBucketValue(i int) int64 {
+	return percentileBucketValues[i]
+}
+
+// PercentileBucketIndex returns the index of the bucket for the value, which is the first bucket with an
+// upper bound greater than or equal to the value. Values less than or equal to 0 map to the first bucket.
+func PercentileBucketIndex(v int64) int {
+	if v <= 0 {
+		return 0
+	}
+	if v <= 4 {
+		return int(v - 1)
+	}
+
+	shift := 63 - bits.LeadingZeros64(uint64(v))
+	if shift%2 != 0 {
+		shift--
+	}
+	// v is in [4^k, 4^(k+1)), where the buckets are steps of a third of 4^k, and the values above the last
+	// step belong to the bucket of 4^(k+1)
+	k := shift / 2
+	base := int64(1) << shift
+	delta := base / 3
+	offset := int((v - base + delta - 1) / delta)
+	return min(powerOf4Index[k]+offset, powerOf4Index[k+1])
+}
+
+// PercentileBucket returns the upper bound of the bucket for the value.
+func PercentileBucket(v int64) int64 {
+	return percentileBucketValues[PercentileBucketIndex(v)]
+}
+
+// EstimatePercentiles computes the percentiles, in the range [0, 100], from the counts of each bucket, by
+// linear interpolation within the bucket where each percentile falls. The counts must have
+// PercentileBucketsLength entries. The estimates are 0 when all the counts are 0.
+func EstimatePercentiles(counts []uint64, pcts []float64) []float64 {
+	results := make([]float64, len(pcts))
+
+	var total uint64
+	for _, c := range counts {
+		total += c
+	}
+	if total == 0 {
+		return results
+	}
+
+	pctIdx := 0
+	var prev uint64
+	var prevP float64
+	var prevB int64
+	for i := 0; i < len(percentileBucketValues) && i < len(counts); i++ {
+		next := prev + counts[i]
+		nextP := 100.0 * float64(next) / float64(total)
+		nextB := percentileBucketValues[i]
+		for pctIdx < len(pcts) && nextP >= pcts[pctIdx] {
+			f := (pcts[pctIdx] - prevP) / (nextP - prevP)
+			results[pctIdx] = f*float64(nextB-prevB) + float64(prevB)
+			pctIdx++
+		}
+		if pctIdx >= len(pcts) {
+			break
+		}
+		prev = next
+		prevP = nextP
+		prevB = nextB
+	}
+	return results
+}
+
+// EstimatePercentile computes a single percentile, in the range [0, 100]. See EstimatePercentiles.
+func EstimatePercentile(counts []uint64, p float64) float64 {
+	return EstimatePercentiles(counts, []float64{p})[0]
+}
+
+// PercentileHistogram counts values in the percentile buckets, to estimate percentiles in-process, such as
+// in tests, or in debug pages. The zero value is ready to use, and it is safe for concurrent use.
+//
+// The values of percentile timers are counted in nanoseconds, as in Java Spectator.
+type PercentileHistogram struct {
+	counts [percentileBucketsLength]atomic.Uint64
+}
+
+// NewPercentileHistogram creates an empty *PercentileHistogram.
+func NewPercentileHistogram() *PercentileHistogram {
+	return &PercentileHistogram{}
+}
+
+// Record counts the value in its bucket. Negative values are ignored, as they are by the meters.
+func (h *PercentileHistogram) Record(v int64) {
+	if v >= 0 {
+		h.counts[PercentileBucketIndex(v)].Add(1)
+	}
+}
+
+// Count returns the number of values recorded.
+func (h *PercentileHistogram) Count() uint64 {
+	var total uint64
+	for i := range h.counts {
+		total += h.counts[i].Load()
+	}
+	return total
+}
+
+// Counts returns a copy of the count of each bucket.
+func (h *PercentileHistogram) Counts() []uint64 {
+	counts := make([]uint64, len(h.counts))
+	for i := range h.counts {
+		counts[i] = h.counts[i].Load()
+	}
+	return counts
+}
+
+// Percentile estimates the percentile p, in the range [0, 100], of the values recorded.
+func (h *PercentileHistogram) Percentile(p float64) float64 {
+	return EstimatePercentile(h.Counts(), p)
+}
+
+// Percentiles estimates several percentiles, in the range [0, 100], from the same snapshot of the counts.
+func (h *PercentileHistogram) Percentiles(pcts ...float64) []float64 {
+	return EstimatePercentiles(h.Counts(), pcts)
+}
+
+// Reset sets the count of each bucket to 0.
+func (h *PercentileHistogram) Reset() {
+	for i := range h.counts {
+		h.counts[i].Store(0)
+	}
+}
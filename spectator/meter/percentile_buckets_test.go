@@ -0,0 +1,153 @@
+package meter
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestPercentileBuckets_Layout(t *testing.T) {
+	if PercentileBucketsLength() != 276 {
+		t.Errorf("Expected 276 buckets, got %d", PercentileBucketsLength())
+	}
+
+	expectedFirst := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 16, 21, 26, 31, 36, 41, 46, 51, 56, 64}
+	for i, expected := range expectedFirst {
+		if PercentileBucketValue(i) != expected {
+			t.Errorf("Expected bucket %d to be %d, got %d", i, expected, PercentileBucketValue(i))
+		}
+	}
+
+	if PercentileBucketValue(PercentileBucketsLength()-1) != math.MaxInt64 {
+		t.Errorf("Expected last bucket to be MaxInt64, got %d", PercentileBucketValue(PercentileBucketsLength()-1))
+	}
+
+	for i := 1; i < PercentileBucketsLength(); i++ {
+		if PercentileBucketValue(i) <= PercentileBucketValue(i-1) {
+			t.Errorf("Expected buckets to be increasing at %d", i)
+		}
+	}
+}
+
+// linearBucketIndex is the reference implementation of PercentileBucketIndex.
+func linearBucketIndex(v int64) int {
+	for i := 0; i < PercentileBucketsLength(); i++ {
+		if v <= PercentileBucketValue(i) {
+			return i
+		}
+	}
+	return PercentileBucketsLength() - 1
+}
+
+func TestPercentileBucketIndex(t *testing.T) {
+	values := []int64{-1, 0, math.MaxInt64, math.MaxInt64 - 1}
+	for i := 0; i < PercentileBucketsLength()-1; i++ {
+		b := PercentileBucketValue(i)
+		values = append(values, b-1, b, b+1)
+	}
+	for v := int64(0); v < 100_000; v++ {
+		values = append(values, v)
+	}
+
+	for _, v := range values {
+		if got, expected := PercentileBucketIndex(v), linearBucketIndex(v); got != expected {
+			t.Errorf("Expected index of %d to be %d, got %d", v, expected, got)
+		}
+	}
+}
+
+func TestPercentileBucket(t *testing.T) {
+	cases := map[int64]int64{
+		0:         1,
+		4:         4,
+		15:        16,
+		16:        16,
+		17:        21,
+		1_000_000: 1_048_576,
+	}
+	for v, expected := range cases {
+		if got := PercentileBucket(v); got != expected {
+			t.Errorf("Expected bucket of %d to be %d, got %d", v, expected, got)
+		}
+	}
+}
+
+func TestEstimatePercentiles_Empty(t *testing.T) {
+	counts := make([]uint64, PercentileBucketsLength())
+	results := EstimatePercentiles(counts, []float64{50, 99})
+	if results[0] != 0 || results[1] != 0 {
+		t.Errorf("Expected 0 estimates, got %v", results)
+	}
+}
+
+func TestPercentileHistogram_Percentile(t *testing.T) {
+	h := NewPercentileHistogram()
+	for i := int64(0); i < 100_000; i++ {
+		h.Record(i)
+	}
+
+	if h.Count() != 100_000 {
+		t.Errorf("Expected count 100000, got %d", h.Count())
+	}
+
+	for _, p := range []float64{25, 50, 90, 99, 99.9} {
+		expected := p * 1_000
+		actual := h.Percentile(p)
+		if math.Abs(actual-expected)/expected > 0.1 {
+			t.Errorf("Expected p%v to be about %v, got %v", p, expected, actual)
+		}
+	}
+
+	results := h.Percentiles(0, 100)
+	if results[0] != 0 {
+		t.Errorf("Expected p0 to be 0, got %v", results[0])
+	}
+	if results[1] < 99_999 || results[1] > float64(PercentileBucket(99_999)) {
+		t.Errorf("Expected p100 to be in the last bucket, got %v", results[1])
+	}
+}
+
+func TestPercentileHistogram_SingleValue(t *testing.T) {
+	var h PercentileHistogram
+	h.Record(1_000_000)
+	h.Record(-1)
+
+	if h.Count() != 1 {
+		t.Errorf("Expected negative values to be ignored, got count %d", h.Count())
+	}
+
+	p99 := h.Percentile(99)
+	lower := float64(PercentileBucketValue(PercentileBucketIndex(1_000_000) - 1))
+	upper := float64(PercentileBucket(1_000_000))
+	if p99 < lower || p99 > upper {
+		t.Errorf("Expected p99 within (%v, %v], got %v", lower, upper, p99)
+	}
+}
+
+func TestPercentileHistogram_Reset(t *testing.T) {
+	h := NewPercentileHistogram()
+	h.Record(42)
+	h.Reset()
+	if h.Count() != 0 {
+		t.Errorf("Expected count 0 after reset, got %d", h.Count())
+	}
+}
+
+func TestPercentileHistogram_Concurrent(t *testing.T) {
+	h := NewPercentileHistogram()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := int64(0); j < 1000; j++ {
+				h.Record(j)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if h.Count() != 8000 {
+		t.Errorf("Expected count 8000, got %d", h.Count())
+	}
+}
@@ -3,6 +3,7 @@ package meter
 import (
 	"fmt"
 	"github.com/Netflix/spectator-go/v2/spectator/writer"
+	"math"
 )
 
 // PercentileDistributionSummary is a distribution summary used to track the
@@ -11,6 +12,8 @@ type PercentileDistributionSummary struct {
 	id              *Id
 	writer          writer.Writer
 	meterTypeSymbol string
+	min             int64
+	max             int64
 }
 
 func (p *PercentileDistributionSummary) MeterId() *Id {
@@ -19,12 +22,25 @@ func (p *PercentileDistributionSummary) MeterId() *Id {
 
 // NewPercentileDistributionSummary creates a new *PercentileDistributionSummary using the meter identifier.
 func NewPercentileDistributionSummary(id *Id, writer writer.Writer) *PercentileDistributionSummary {
-	return &PercentileDistributionSummary{id, writer, "D"}
+	return &PercentileDistributionSummary{id, writer, "D", 0, math.MaxInt64}
 }
 
-// Record records an amount to track within the distribution.
+// NewPercentileDistributionSummaryWithRange creates a new *PercentileDistributionSummary, which clamps the
+// recorded values into the range [min, max], like the withRange option of the Java Spectator
+// PercentileDistributionSummary builder. This limits the number of percentile buckets used by the
+// distribution summary, and so the number of series. If min is greater than max, they are swapped.
+func NewPercentileDistributionSummaryWithRange(id *Id, writer writer.Writer, min int64, max int64) *PercentileDistributionSummary {
+	if min > max {
+		min, max = max, min
+	}
+	return &PercentileDistributionSummary{id, writer, "D", min, max}
+}
+
+// Record records an amount to track within the distribution. Negative amounts are ignored, and the other
+// amounts are clamped into the range of the distribution summary.
 func (p *PercentileDistributionSummary) Record(amount int64) {
 	if amount >= 0 {
+		amount = max(p.min, min(p.max, amount))
 		var line = fmt.Sprintf("%s:%s:%d", p.meterTypeSymbol, p.id.spectatordId, amount)
 		p.writer.Write(line)
 	}
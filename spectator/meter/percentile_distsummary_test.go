@@ -60,3 +60,23 @@ func TestPercentileDistributionSummary_RecordMultipleValues(t *testing.T) {
 		}
 	}
 }
+
+func TestPercentileDistributionSummary_RecordWithRange(t *testing.T) {
+	id := NewId("recordPercentileRange", nil)
+	w := writer.MemoryWriter{}
+	ds := NewPercentileDistributionSummaryWithRange(id, &w, 10, 1000)
+	ds.Record(-100)
+	ds.Record(1)
+	ds.Record(100)
+	ds.Record(5000)
+
+	expectedLines := []string{"D:recordPercentileRange:10", "D:recordPercentileRange:100", "D:recordPercentileRange:1000"}
+	if len(w.Lines()) != len(expectedLines) {
+		t.Fatalf("Expected %d lines, got %v", len(expectedLines), w.Lines())
+	}
+	for i, line := range w.Lines() {
+		if line != expectedLines[i] {
+			t.Errorf("Expected line to be %s, got %s", expectedLines[i], line)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package meter
 import (
 	"fmt"
 	"github.com/Netflix/spectator-go/v2/spectator/writer"
+	"math"
 	"time"
 )
 
@@ -12,22 +13,37 @@ type PercentileTimer struct {
 	id              *Id
 	writer          writer.Writer
 	meterTypeSymbol string
+	min             time.Duration
+	max             time.Duration
 }
 
 func NewPercentileTimer(
 	id *Id,
 	writer writer.Writer,
 ) *PercentileTimer {
-	return &PercentileTimer{id, writer, "T"}
+	return &PercentileTimer{id, writer, "T", 0, math.MaxInt64}
+}
+
+// NewPercentileTimerWithRange creates a new *PercentileTimer, which clamps the recorded values into the
+// range [min, max], like the withRange option of the Java Spectator PercentileTimer builder. This limits
+// the number of percentile buckets used by the timer, and so the number of series. If min is greater than
+// max, they are swapped.
+func NewPercentileTimerWithRange(id *Id, writer writer.Writer, min time.Duration, max time.Duration) *PercentileTimer {
+	if min > max {
+		min, max = max, min
+	}
+	return &PercentileTimer{id, writer, "T", min, max}
 }
 
 func (t *PercentileTimer) MeterId() *Id {
 	return t.id
 }
 
-// Record records the value for a single event.
+// Record records the value for a single event. Negative values are ignored, and the other values are
+// clamped into the range of the timer.
 func (t *PercentileTimer) Record(amount time.Duration) {
 	if amount >= 0 {
+		amount = max(t.min, min(t.max, amount))
 		var line = fmt.Sprintf("%s:%s:%f", t.meterTypeSymbol, t.id.spectatordId, amount.Seconds())
 		t.writer.Write(line)
 	}
@@ -70,3 +70,27 @@ func TestPercentileTimer_RecordMultipleValues(t *testing.T) {
 		}
 	}
 }
+
+func TestPercentileTimer_RecordWithRange(t *testing.T) {
+	id := NewId("recordPercentileTimerRange", nil)
+	w := writer.MemoryWriter{}
+	pt := NewPercentileTimerWithRange(id, &w, time.Second, 10*time.Millisecond)
+	pt.Record(-time.Second)
+	pt.Record(time.Millisecond)
+	pt.Record(100 * time.Millisecond)
+	pt.Record(time.Minute)
+
+	expectedLines := []string{
+		"T:recordPercentileTimerRange:0.010000",
+		"T:recordPercentileTimerRange:0.100000",
+		"T:recordPercentileTimerRange:1.000000",
+	}
+	if len(w.Lines()) != len(expectedLines) {
+		t.Fatalf("Expected %d lines, got %v", len(expectedLines), w.Lines())
+	}
+	for i, line := range w.Lines() {
+		if line != expectedLines[i] {
+			t.Errorf("Expected line to be %s, got %s", expectedLines[i], line)
+		}
+	}
+}
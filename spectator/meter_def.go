@@ -0,0 +1,156 @@
+package spectator
+
+import (
+	"github.com/Netflix/spectator-go/v2/spectator/meter"
+	"strings"
+	"sync"
+)
+
+// inlineTagValues is the number of tag values stored in the cache key without allocation. Definitions with
+// more tag keys join the remaining values into a string.
+const inlineTagValues = 4
+
+// meterDefKey identifies a cached meter by registry id, and by positional tag values. The number of values
+// is part of the key, so that missing values are not confused with empty ones.
+type meterDefKey struct {
+	registry uint64
+	count    int
+	values   [inlineTagValues]string
+	rest     string
+}
+
+// MeterDef is a meter template, with a name and tag keys declared once, for high-throughput code. With
+// returns a cached meter for the tag values, so that no map is built after the first call for the values.
+//
+//	var requests = spectator.CounterDef("server.requests", "method", "status")
+//
+//	func handle(r spectator.Registry) {
+//		requests.With(r, "GET", "200").Increment()
+//	}
+//
+// The meters are cached forever, so only use tags with a bounded set of values. The meters are only cached
+// for the registries created by NewRegistry, and their scoped views; the other implementations of
+// Registry create a meter on every call. A MeterDef is safe for concurrent use.
+type MeterDef[M any] struct {
+	name   string
+	keys   []string
+	create func(r Registry, name string, tags map[string]string) M
+
+	mu    sync.RWMutex
+	cache map[meterDefKey]M
+}
+
+func newMeterDef[M any](name string, keys []string, create func(Registry, string, map[string]string) M) *MeterDef[M] {
+	return &MeterDef[M]{
+		name:   name,
+		keys:   append([]string(nil), keys...),
+		create: create,
+		cache:  make(map[meterDefKey]M),
+	}
+}
+
+// Name returns the meter name.
+func (d *MeterDef[M]) Name() string {
+	return d.name
+}
+
+// Keys returns a copy of the tag keys, in the order expected by With.
+func (d *MeterDef[M]) Keys() []string {
+	return append([]string(nil), d.keys...)
+}
+
+// With returns the meter created by the registry for the tag values, which are given in the same order
+// as the tag keys of the definition. The meter is created through the registry, so the common tags, scope
+// and meter filters of the registry apply. If the number of values does not match the number of keys,
+// then the error is logged, and the extra keys or values are ignored.
+func (d *MeterDef[M]) With(r Registry, values ...string) M {
+	sr, ok := r.(*spectatordRegistry)
+	if !ok {
+		return d.create(r, d.name, d.tags(r, values))
+	}
+
+	key := meterDefKey{registry: sr.id, count: len(values)}
+	copy(key.values[:], values)
+	if len(values) > inlineTagValues {
+		key.rest = strings.Join(values[inlineTagValues:], "\x00")
+	}
+
+	d.mu.RLock()
+	m, ok := d.cache[key]
+	d.mu.RUnlock()
+	if ok {
+		return m
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if m, ok := d.cache[key]; ok {
+		return m
+	}
+
+	m = d.create(r, d.name, d.tags(r, values))
+	d.cache[key] = m
+	return m
+}
+
+// tags returns the tags for the values, and logs an error if the number of values is wrong.
+func (d *MeterDef[M]) tags(r Registry, values []string) map[string]string {
+	if len(values) != len(d.keys) {
+		r.GetLogger().Errorf("Meter definition %s expects %d tag values for keys %v, got %d", d.name, len(d.keys), d.keys, len(values))
+	}
+	tags := make(map[string]string, len(d.keys))
+	for i := 0; i < len(d.keys) && i < len(values); i++ {
+		tags[d.keys[i]] = values[i]
+	}
+	return tags
+}
+
+// AgeGaugeDef declares an age gauge template. See MeterDef.
+func AgeGaugeDef(name string, keys ...string) *MeterDef[*meter.AgeGauge] {
+	return newMeterDef(name, keys, Registry.AgeGauge)
+}
+
+// CounterDef declares a counter template. See MeterDef.
+func CounterDef(name string, keys ...string) *MeterDef[*meter.Counter] {
+	return newMeterDef(name, keys, Registry.Counter)
+}
+
+// DistributionSummaryDef declares a distribution summary template. See MeterDef.
+func DistributionSummaryDef(name string, keys ...string) *MeterDef[*meter.DistributionSummary] {
+	return newMeterDef(name, keys, Registry.DistributionSummary)
+}
+
+// GaugeDef declares a gauge template. See MeterDef.
+func GaugeDef(name string, keys ...string) *MeterDef[*meter.Gauge] {
+	return newMeterDef(name, keys, Registry.Gauge)
+}
+
+// MaxGaugeDef declares a max gauge template. See MeterDef.
+func MaxGaugeDef(name string, keys ...string) *MeterDef[*meter.MaxGauge] {
+	return newMeterDef(name, keys, Registry.MaxGauge)
+}
+
+// MonotonicCounterDef declares a monotonic counter template. See MeterDef.
+func MonotonicCounterDef(name string, keys ...string) *MeterDef[*meter.MonotonicCounter] {
+	return newMeterDef(name, keys, Registry.MonotonicCounter)
+}
+
+// MonotonicCounterUintDef declares a monotonic counter template for uint64 values. See MeterDef.
+func MonotonicCounterUintDef(name string, keys ...string) *MeterDef[*meter.MonotonicCounterUint] {
+	return newMeterDef(name, keys, Registry.MonotonicCounterUint)
+}
+
+// PercentileDistributionSummaryDef declares a percentile distribution summary template. See MeterDef.
+func PercentileDistributionSummaryDef(name string, keys ...string) *MeterDef[*meter.PercentileDistributionSummary] {
+	return newMeterDef(name, keys, Registry.PercentileDistributionSummary)
+}
+
+// PercentileTimerDef declares a percentile timer template. See MeterDef.
+func PercentileTimerDef(name string, keys ...string) *MeterDef[*meter.PercentileTimer] {
+	return newMeterDef(name, keys, Registry.PercentileTimer)
+}
+
+// TimerDef declares a timer template. See MeterDef.
+func TimerDef(name string, keys ...string) *MeterDef[*meter.Timer] {
+	return newMeterDef(name, keys, Registry.Timer)
+}
@@ -0,0 +1,104 @@
+package spectator
+
+import (
+	"github.com/Netflix/spectator-go/v2/spectator/writer"
+	"testing"
+)
+
+func TestMeterDef_With(t *testing.T) {
+	r := NewTestRegistryWithCommonTags()
+	mw := r.GetWriter().(*writer.MemoryWriter)
+	requests := CounterDef("server.requests", "method")
+
+	counter := requests.With(r, "GET")
+	counter.Increment()
+
+	if requests.With(r, "GET") != counter {
+		t.Errorf("Expected the cached counter for the same values")
+	}
+	if requests.With(r, "POST") == counter {
+		t.Errorf("Expected a different counter for different values")
+	}
+	if requests.With(r.(ScopedRegistry).Scoped("lib", nil), "GET") == counter {
+		t.Errorf("Expected a different counter for a different registry")
+	}
+
+	expected := "server.requests|extra-tag|foo|method|GET"
+	if counter.MeterId().MapKey() != expected {
+		t.Errorf("Expected Id '%s', got '%s'", expected, counter.MeterId().MapKey())
+	}
+	if len(mw.Lines()) != 1 {
+		t.Errorf("Expected one line, got %v", mw.Lines())
+	}
+}
+
+func TestMeterDef_ManyKeys(t *testing.T) {
+	r := NewTestRegistry()
+	def := TimerDef("test_timer", "a", "b", "c", "d", "e", "f")
+
+	first := def.With(r, "1", "2", "3", "4", "5", "6")
+	if def.With(r, "1", "2", "3", "4", "5", "6") != first {
+		t.Errorf("Expected the cached timer for the same values")
+	}
+	if def.With(r, "1", "2", "3", "4", "5", "7") == first {
+		t.Errorf("Expected a different timer when a value after the inline values differs")
+	}
+	if first.MeterId().Tags()["f"] != "6" {
+		t.Errorf("Expected tag f=6, got %v", first.MeterId().Tags())
+	}
+}
+
+func TestMeterDef_WrongNumberOfValues(t *testing.T) {
+	log := &captureLogger{}
+	config, _ := NewConfigWithOptions(WithLocation("memory"), WithLogger(log))
+	r, _ := NewRegistry(config)
+
+	gauge := GaugeDef("test_gauge", "a", "b").With(r, "1")
+
+	if len(gauge.MeterId().Tags()) != 1 || gauge.MeterId().Tags()["a"] != "1" {
+		t.Errorf("Expected only tag a=1, got %v", gauge.MeterId().Tags())
+	}
+	if len(log.Errors()) != 1 {
+		t.Errorf("Expected one error, got %v", log.Errors())
+	}
+}
+
+func TestMeterDef_MissingValueIsNotEmptyValue(t *testing.T) {
+	r := NewTestRegistry()
+	def := GaugeDef("test_gauge", "a", "b")
+
+	missing := def.With(r, "1")
+	empty := def.With(r, "1", "")
+	if missing == empty {
+		t.Errorf("Expected different gauges for a missing and an empty value")
+	}
+}
+
+// mapRegistry is a Registry implementation which is not comparable.
+type mapRegistry struct {
+	Registry
+	labels map[string]string
+}
+
+func TestMeterDef_NonComparableRegistry(t *testing.T) {
+	r := mapRegistry{Registry: NewTestRegistry(), labels: map[string]string{}}
+	requests := CounterDef("server.requests", "method")
+
+	counter := requests.With(r, "GET")
+	if counter.MeterId().Tags()["method"] != "GET" {
+		t.Errorf("Expected tag method=GET, got %v", counter.MeterId().Tags())
+	}
+}
+
+func TestMeterDef_CachedWithDoesNotAllocate(t *testing.T) {
+	r := NewTestRegistry()
+	requests := CounterDef("server.requests", "method", "status")
+	requests.With(r, "GET", "200")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		requests.With(r, "GET", "200")
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations for a cached meter, got %.1f", allocs)
+	}
+}
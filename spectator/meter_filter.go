@@ -0,0 +1,154 @@
+package spectator
+
+import (
+	"github.com/Netflix/spectator-go/v2/spectator/meter"
+	"hash/fnv"
+	"path"
+)
+
+// FilterReply is the decision of a MeterFilter.
+type FilterReply int
+
+const (
+	// FilterNeutral passes the Id, which may have been transformed, to the next filter in the chain. If
+	// every filter is neutral, the meter is accepted.
+	FilterNeutral FilterReply = iota
+	// FilterAccept accepts the meter, without evaluating the rest of the chain.
+	FilterAccept
+	// FilterDeny denies the meter, without evaluating the rest of the chain. The updates of a denied meter
+	// are discarded.
+	FilterDeny
+)
+
+// MeterFilter decides whether a meter is written, and may transform its Id. Filters are evaluated in
+// order, when a meter is created by the Registry, so they should not be expensive.
+type MeterFilter interface {
+	Filter(id *meter.Id) (*meter.Id, FilterReply)
+}
+
+// MeterFilterFunc adapts a function to the MeterFilter interface.
+type MeterFilterFunc func(id *meter.Id) (*meter.Id, FilterReply)
+
+func (f MeterFilterFunc) Filter(id *meter.Id) (*meter.Id, FilterReply) {
+	return f(id)
+}
+
+// applyMeterFilters evaluates the chain against the Id, and returns the transformed Id and whether the
+// meter is accepted.
+func applyMeterFilters(filters []MeterFilter, id *meter.Id) (*meter.Id, bool) {
+	for _, f := range filters {
+		filtered, reply := f.Filter(id)
+		if filtered != nil {
+			id = filtered
+		}
+		switch reply {
+		case FilterAccept:
+			return id, true
+		case FilterDeny:
+			return id, false
+		}
+	}
+	return id, true
+}
+
+// matchesAny reports whether the name matches one of the glob patterns, as defined by path.Match.
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// DenyNames denies the meters with a name matching one of the glob patterns, as defined by path.Match.
+func DenyNames(patterns ...string) MeterFilter {
+	return MeterFilterFunc(func(id *meter.Id) (*meter.Id, FilterReply) {
+		if matchesAny(patterns, id.Name()) {
+			return id, FilterDeny
+		}
+		return id, FilterNeutral
+	})
+}
+
+// AcceptNames accepts the meters with a name matching one of the glob patterns, as defined by path.Match,
+// without evaluating the rest of the chain. Combine it with a final DenyNames("*") to only allow the
+// matching meters.
+func AcceptNames(patterns ...string) MeterFilter {
+	return MeterFilterFunc(func(id *meter.Id) (*meter.Id, FilterReply) {
+		if matchesAny(patterns, id.Name()) {
+			return id, FilterAccept
+		}
+		return id, FilterNeutral
+	})
+}
+
+// RenameMeter renames the meters named `from` to `to`, keeping their tags.
+func RenameMeter(from string, to string) MeterFilter {
+	return MeterFilterFunc(func(id *meter.Id) (*meter.Id, FilterReply) {
+		if id.Name() == from {
+			return meter.NewIdFromTags(to, id.TagList()...), FilterNeutral
+		}
+		return id, FilterNeutral
+	})
+}
+
+// DenyTags removes the tags with the listed keys, such as high-cardinality tags, from every meter.
+func DenyTags(keys ...string) MeterFilter {
+	deny := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		deny[k] = true
+	}
+	return MeterFilterFunc(func(id *meter.Id) (*meter.Id, FilterReply) {
+		return retainTags(id, func(k string) bool { return !deny[k] }), FilterNeutral
+	})
+}
+
+// AllowTags removes the tags with keys that are not listed from every meter. The filters run after the
+// extra common tags of the Config are added, so list their keys to keep them.
+func AllowTags(keys ...string) MeterFilter {
+	allow := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		allow[k] = true
+	}
+	return MeterFilterFunc(func(id *meter.Id) (*meter.Id, FilterReply) {
+		return retainTags(id, func(k string) bool { return allow[k] }), FilterNeutral
+	})
+}
+
+// retainTags returns the Id with only the tags for which keep returns true, or the same Id if all the
+// tags are kept.
+func retainTags(id *meter.Id, keep func(k string) bool) *meter.Id {
+	all := id.TagList()
+	tags := all[:0]
+	for _, t := range all {
+		if keep(t.Key) {
+			tags = append(tags, t)
+		}
+	}
+	if len(tags) == len(all) {
+		return id
+	}
+	return meter.NewIdFromTags(id.Name(), tags...)
+}
+
+// sampleBuckets is the resolution of the sample rate of SampleNames.
+const sampleBuckets = 1_000_000
+
+// SampleNames keeps a fraction of the series with a name matching the glob pattern, and denies the others.
+// The decision is made per Id, from a hash of its name and tags, so that a given series is either always
+// written or never written, across processes and restarts. A rate of 0.1 keeps about 10% of the series.
+func SampleNames(pattern string, rate float64) MeterFilter {
+	return MeterFilterFunc(func(id *meter.Id) (*meter.Id, FilterReply) {
+		if ok, _ := path.Match(pattern, id.Name()); !ok {
+			return id, FilterNeutral
+		}
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(id.MapKey()))
+		// the low bits of FNV are the best distributed for inputs that only differ in their last bytes
+		if float64(h.Sum64()%sampleBuckets)/sampleBuckets < rate {
+			return id, FilterNeutral
+		}
+		return id, FilterDeny
+	})
+}
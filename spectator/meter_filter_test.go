@@ -0,0 +1,131 @@
+package spectator
+
+import (
+	"fmt"
+	"github.com/Netflix/spectator-go/v2/spectator/meter"
+	"github.com/Netflix/spectator-go/v2/spectator/writer"
+	"testing"
+)
+
+func newFilteredRegistry(t *testing.T, filters ...MeterFilter) (Registry, *writer.MemoryWriter) {
+	config, err := NewConfigWithOptions(
+		WithLocation("memory"),
+		WithCommonTags(map[string]string{"nf.app": "api"}),
+		WithMeterFilters(filters...),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	r, _ := NewRegistry(config)
+	return r, r.GetWriter().(*writer.MemoryWriter)
+}
+
+func TestMeterFilters_Chain(t *testing.T) {
+	testCases := []struct {
+		filters  []MeterFilter
+		name     string
+		accepted bool
+	}{
+		{[]MeterFilter{}, "server.requests", true},
+		{[]MeterFilter{DenyNames("noisy.*")}, "noisy.requests", false},
+		{[]MeterFilter{DenyNames("noisy.*")}, "server.requests", true},
+		{[]MeterFilter{AcceptNames("server.*"), DenyNames("*")}, "server.requests", true},
+		{[]MeterFilter{AcceptNames("server.*"), DenyNames("*")}, "client.requests", false},
+		{[]MeterFilter{DenyNames("server.*"), AcceptNames("*")}, "server.requests", false},
+	}
+
+	for _, tc := range testCases {
+		_, accepted := applyMeterFilters(tc.filters, meter.NewId(tc.name, nil))
+		if accepted != tc.accepted {
+			t.Errorf("Expected accepted=%v for %s, got %v", tc.accepted, tc.name, accepted)
+		}
+	}
+}
+
+func TestMeterFilters_DeniedMeterIsNotWritten(t *testing.T) {
+	r, mw := newFilteredRegistry(t, DenyNames("noisy.*"))
+
+	r.Counter("noisy.requests", nil).Increment()
+	r.GaugeWithTTL("noisy.gauge", nil, 0).Set(1)
+	r.TimerWithId(r.NewId("noisy.timer", nil)).Record(1)
+	r.Counter("server.requests", nil).Increment()
+
+	expected := "c:server.requests,nf.app=api:1"
+	if len(mw.Lines()) != 1 || mw.Lines()[0] != expected {
+		t.Errorf("Expected only '%s', got %v", expected, mw.Lines())
+	}
+}
+
+func TestMeterFilters_Rename(t *testing.T) {
+	r, mw := newFilteredRegistry(t, RenameMeter("legacy.requests", "server.requests"))
+
+	counter := r.Counter("legacy.requests", nil)
+	counter.Increment()
+
+	if counter.MeterId().Name() != "server.requests" {
+		t.Errorf("Expected server.requests, got %s", counter.MeterId().Name())
+	}
+	expected := "c:server.requests,nf.app=api:1"
+	if len(mw.Lines()) != 1 || mw.Lines()[0] != expected {
+		t.Errorf("Expected '%s', got %v", expected, mw.Lines())
+	}
+}
+
+func TestMeterFilters_Tags(t *testing.T) {
+	r, _ := newFilteredRegistry(t, DenyTags("request.id"))
+	id := r.Counter("server.requests", map[string]string{"request.id": "1234", "status": "200"}).MeterId()
+
+	expected := map[string]string{"status": "200", "nf.app": "api"}
+	if fmt.Sprint(id.Tags()) != fmt.Sprint(expected) {
+		t.Errorf("Expected tags %v, got %v", expected, id.Tags())
+	}
+
+	// common tags are added before the filters, so they are only kept when they are in the allow list
+	r, _ = newFilteredRegistry(t, AllowTags("status", "nf.app"))
+	id = r.Counter("server.requests", map[string]string{"method": "GET", "status": "200"}).MeterId()
+
+	if fmt.Sprint(id.Tags()) != fmt.Sprint(expected) {
+		t.Errorf("Expected tags %v, got %v", expected, id.Tags())
+	}
+}
+
+func TestMeterFilters_SeeCommonTags(t *testing.T) {
+	var seen map[string]string
+	r, _ := newFilteredRegistry(t, MeterFilterFunc(func(id *meter.Id) (*meter.Id, FilterReply) {
+		seen = id.Tags()
+		return id, FilterNeutral
+	}))
+	id := r.Counter("server.requests", map[string]string{"status": "200"}).MeterId()
+
+	expected := map[string]string{"status": "200", "nf.app": "api"}
+	if fmt.Sprint(seen) != fmt.Sprint(expected) {
+		t.Errorf("Expected the filter to see tags %v, got %v", expected, seen)
+	}
+	if fmt.Sprint(id.Tags()) != fmt.Sprint(expected) {
+		t.Errorf("Expected tags %v, got %v", expected, id.Tags())
+	}
+}
+
+func TestMeterFilters_Sample(t *testing.T) {
+	filters := []MeterFilter{SampleNames("debug.*", 0.1)}
+
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		id := meter.NewId("debug.requests", map[string]string{"i": fmt.Sprint(i)})
+		_, accepted := applyMeterFilters(filters, id)
+		// the decision is stable for a given Id
+		if _, again := applyMeterFilters(filters, id); again != accepted {
+			t.Fatalf("Expected a stable decision for %v", id)
+		}
+		if accepted {
+			kept++
+		}
+	}
+	if kept < 50 || kept > 150 {
+		t.Errorf("Expected about 100 of 1000 series to be kept, got %d", kept)
+	}
+
+	if _, accepted := applyMeterFilters(filters, meter.NewId("server.requests", nil)); !accepted {
+		t.Errorf("Expected meters that do not match the pattern to be accepted")
+	}
+}
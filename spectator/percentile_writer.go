@@ -0,0 +1,157 @@
+package spectator
+
+import (
+	"github.com/Netflix/spectator-go/v2/spectator/meter"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PercentileWriter is a writer which keeps a meter.PercentileHistogram for each percentile timer and
+// percentile distribution summary written to it, so that their percentiles can be inspected in-process,
+// like the MemoryWriter allows the lines to be inspected. The lines of the other meters are ignored.
+//
+//	w := spectator.NewPercentileWriter()
+//	t := meter.NewPercentileTimer(meter.NewId("server.requestLatency", nil), w)
+//	...
+//	p99, ok := w.Percentile(t.MeterId(), 99)
+//
+// The percentiles of timers are in seconds, and the percentiles of distribution summaries are in the
+// recorded units. A PercentileWriter is safe for concurrent use.
+type PercentileWriter struct {
+	mu         sync.RWMutex
+	histograms map[string]*percentileSeries
+}
+
+type percentileSeries struct {
+	id        *meter.Id
+	symbol    string
+	histogram *meter.PercentileHistogram
+}
+
+// NewPercentileWriter creates an empty *PercentileWriter.
+func NewPercentileWriter() *PercentileWriter {
+	return &PercentileWriter{histograms: make(map[string]*percentileSeries)}
+}
+
+func (p *PercentileWriter) Write(line string) {
+	p.record(line)
+}
+
+// WriteBytes records each of the newline separated lines, as written by the buffers.
+func (p *PercentileWriter) WriteBytes(line []byte) {
+	p.WriteString(string(line))
+}
+
+// WriteString records each of the newline separated lines, as written by the buffers.
+func (p *PercentileWriter) WriteString(line string) {
+	for _, l := range strings.Split(line, "\n") {
+		p.record(l)
+	}
+}
+
+func (p *PercentileWriter) record(line string) {
+	if !strings.HasPrefix(line, "T:") && !strings.HasPrefix(line, "D:") {
+		return
+	}
+	symbol, id, value, err := ParseProtocolLine(line)
+	if err != nil {
+		return
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
+	}
+	p.series(id, symbol).histogram.Record(toHistogramValue(symbol, amount))
+}
+
+// toHistogramValue converts a value written by a percentile meter to the unit counted by the histograms.
+// Timers are written in seconds, and counted in nanoseconds.
+func toHistogramValue(symbol string, v float64) int64 {
+	if symbol == "T" {
+		v *= 1e9
+	}
+	return int64(math.Round(v))
+}
+
+// fromHistogramValue converts a percentile estimated by a histogram to the unit of the percentile meter.
+func fromHistogramValue(symbol string, v float64) float64 {
+	if symbol == "T" {
+		return v / 1e9
+	}
+	return v
+}
+
+func (p *PercentileWriter) series(id *meter.Id, symbol string) *percentileSeries {
+	key := symbol + ":" + id.MapKey()
+
+	p.mu.RLock()
+	s, ok := p.histograms[key]
+	p.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if s, ok := p.histograms[key]; ok {
+		return s
+	}
+	s = &percentileSeries{id: id, symbol: symbol, histogram: meter.NewPercentileHistogram()}
+	p.histograms[key] = s
+	return s
+}
+
+// lookup returns the series of the percentile timer or percentile distribution summary with the Id.
+func (p *PercentileWriter) lookup(id *meter.Id) (*percentileSeries, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if s, ok := p.histograms["T:"+id.MapKey()]; ok {
+		return s, true
+	}
+	s, ok := p.histograms["D:"+id.MapKey()]
+	return s, ok
+}
+
+// Histogram returns the histogram of the percentile timer or percentile distribution summary with the Id,
+// which is counted in nanoseconds for timers. It reports false if nothing was recorded for the Id.
+func (p *PercentileWriter) Histogram(id *meter.Id) (*meter.PercentileHistogram, bool) {
+	s, ok := p.lookup(id)
+	if !ok {
+		return nil, false
+	}
+	return s.histogram, true
+}
+
+// Percentile estimates the percentile pct, in the range [0, 100], of the percentile timer or percentile
+// distribution summary with the Id. It reports false if nothing was recorded for the Id.
+func (p *PercentileWriter) Percentile(id *meter.Id, pct float64) (float64, bool) {
+	s, ok := p.lookup(id)
+	if !ok {
+		return 0, false
+	}
+	return fromHistogramValue(s.symbol, s.histogram.Percentile(pct)), true
+}
+
+// Ids returns the Ids of the meters recorded.
+func (p *PercentileWriter) Ids() []*meter.Id {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	ids := make([]*meter.Id, 0, len(p.histograms))
+	for _, s := range p.histograms {
+		ids = append(ids, s.id)
+	}
+	return ids
+}
+
+// Reset discards the recorded histograms.
+func (p *PercentileWriter) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.histograms = make(map[string]*percentileSeries)
+}
+
+func (p *PercentileWriter) Close() error {
+	return nil
+}
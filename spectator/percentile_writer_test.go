@@ -0,0 +1,71 @@
+package spectator
+
+import (
+	"github.com/Netflix/spectator-go/v2/spectator/meter"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPercentileWriter_Timer(t *testing.T) {
+	w := NewPercentileWriter()
+	pt := meter.NewPercentileTimer(meter.NewId("server.requestLatency", map[string]string{"status": "200"}), w)
+	for i := 1; i <= 1000; i++ {
+		pt.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	p99, ok := w.Percentile(pt.MeterId(), 99)
+	if !ok {
+		t.Fatal("Expected the timer to be recorded")
+	}
+	if math.Abs(p99-0.99)/0.99 > 0.1 {
+		t.Errorf("Expected p99 to be about 0.99s, got %v", p99)
+	}
+
+	h, ok := w.Histogram(pt.MeterId())
+	if !ok || h.Count() != 1000 {
+		t.Errorf("Expected a histogram with 1000 values")
+	}
+}
+
+func TestPercentileWriter_DistributionSummary(t *testing.T) {
+	w := NewPercentileWriter()
+	id := meter.NewId("server.responseSize", nil)
+	ds := meter.NewPercentileDistributionSummary(id, w)
+	for i := int64(1); i <= 10_000; i++ {
+		ds.Record(i)
+	}
+
+	p50, ok := w.Percentile(id, 50)
+	if !ok {
+		t.Fatal("Expected the distribution summary to be recorded")
+	}
+	if math.Abs(p50-5000)/5000 > 0.1 {
+		t.Errorf("Expected p50 to be about 5000, got %v", p50)
+	}
+}
+
+func TestPercentileWriter_BufferedLines(t *testing.T) {
+	w := NewPercentileWriter()
+	w.WriteString("D:size:10\nc:requests:1\nD:size:20")
+	w.WriteBytes([]byte("D:size:30"))
+	w.Write("T:latency:not-a-number")
+
+	h, ok := w.Histogram(meter.NewId("size", nil))
+	if !ok || h.Count() != 3 {
+		t.Errorf("Expected 3 values for size")
+	}
+	if len(w.Ids()) != 1 {
+		t.Errorf("Expected only the distribution summary to be recorded, got %v", w.Ids())
+	}
+}
+
+func TestPercentileWriter_Unknown(t *testing.T) {
+	w := NewPercentileWriter()
+	w.Write("D:size:10")
+	w.Reset()
+
+	if _, ok := w.Percentile(meter.NewId("size", nil), 99); ok {
+		t.Error("Expected no percentile after reset")
+	}
+}
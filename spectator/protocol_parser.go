@@ -6,7 +6,37 @@ import (
 	"strings"
 )
 
-// ParseProtocolLine parses a line of the spectator protocol. Utility exposed for testing.
+// meterTypes maps protocol symbols to the names of the meter types.
+var meterTypes = map[string]string{
+	"A": "age-gauge",
+	"c": "counter",
+	"C": "monotonic-counter",
+	"d": "dist-summary",
+	"D": "percentile-dist-summary",
+	"g": "gauge",
+	"m": "max-gauge",
+	"t": "timer",
+	"T": "percentile-timer",
+	"U": "monotonic-counter-uint",
+}
+
+// MeterType returns the name of the meter type for the protocol symbol, such as `counter` for `c`, including
+// the ttl of gauges, such as `gauge(ttl=300s)` for `g,300`. Unknown symbols are named `unknown(symbol)`.
+func MeterType(symbol string) string {
+	base, ttl, hasTTL := strings.Cut(symbol, ",")
+	name, ok := meterTypes[base]
+	if !ok {
+		name = "unknown(" + base + ")"
+	}
+	if hasTTL {
+		name += "(ttl=" + ttl + "s)"
+	}
+	return name
+}
+
+// ParseProtocolLine parses a line of the spectator protocol, `symbol:id:value`, and returns the meter
+// symbol, the Id and the value. The Id is parsed with meter.ParseId, so Ids with invalid characters are
+// reported as errors. Utility exposed for testing.
 func ParseProtocolLine(line string) (string, *meter.Id, string, error) {
 	parts := strings.Split(line, ":")
 	if len(parts) != 3 {
@@ -14,20 +44,12 @@ func ParseProtocolLine(line string) (string, *meter.Id, string, error) {
 	}
 
 	meterSymbol := parts[0]
-	meterId := parts[1]
 	value := parts[2]
 
-	meterIdParts := strings.Split(meterId, ",")
-	name := meterIdParts[0]
-
-	tags := make(map[string]string)
-	for _, tag := range meterIdParts[1:] {
-		kv := strings.Split(tag, "=")
-		if len(kv) != 2 {
-			return "", nil, "", fmt.Errorf("invalid tag format")
-		}
-		tags[kv[0]] = kv[1]
+	meterId, err := meter.ParseId(parts[1])
+	if err != nil {
+		return "", nil, "", err
 	}
 
-	return meterSymbol, meter.NewId(name, tags), value, nil
+	return meterSymbol, meterId, value, nil
 }
@@ -1,6 +1,7 @@
 package spectator
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -63,3 +64,27 @@ func TestParseGaugeWithTTL(t *testing.T) {
 		t.Errorf("Expected '1', got '%s'", value)
 	}
 }
+
+func TestParseProtocolLineReportsInvalidCharacters(t *testing.T) {
+	line := "c:meter id,tag1=value1:1"
+	_, _, _, err := ParseProtocolLine(line)
+
+	if err == nil || !strings.Contains(err.Error(), "invalid character ' ' at offset 5") {
+		t.Errorf("Expected invalid character error, got %v", err)
+	}
+}
+
+func TestMeterType(t *testing.T) {
+	testCases := map[string]string{
+		"c":     "counter",
+		"g,300": "gauge(ttl=300s)",
+		"T":     "percentile-timer",
+		"x":     "unknown(x)",
+	}
+
+	for symbol, expected := range testCases {
+		if actual := MeterType(symbol); actual != expected {
+			t.Errorf("Expected '%s' for '%s', got '%s'", expected, symbol, actual)
+		}
+	}
+}
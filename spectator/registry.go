@@ -7,10 +7,14 @@
 package spectator
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/Netflix/spectator-go/v2/spectator/logger"
 	"github.com/Netflix/spectator-go/v2/spectator/meter"
 	"github.com/Netflix/spectator-go/v2/spectator/writer"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -49,13 +53,96 @@ type Registry interface {
 	Close()
 }
 
-// Used to validate that spectatordRegistry implements Registry at build time.
-var _ Registry = (*spectatordRegistry)(nil)
+// StatsRegistry is implemented by registries which report the delivery statistics of their writer. It is
+// optional, so callers should check for it with a type assertion.
+type StatsRegistry interface {
+	GetWriterStats() (writer.Stats, bool)
+}
+
+// ScopedRegistry is implemented by registries which create views for a namespace. It is optional, so
+// callers should check for it with a type assertion.
+type ScopedRegistry interface {
+	Scoped(prefix string, tags map[string]string) Registry
+}
+
+// ReconfigurableRegistry is implemented by registries which apply a new configuration at runtime. It is
+// optional, so callers should check for it with a type assertion.
+type ReconfigurableRegistry interface {
+	Reconfigure(config *Config) error
+}
+
+// ShutdownRegistry is implemented by registries which flush and close their writer with a deadline. It is
+// optional, so callers should check for it with a type assertion.
+type ShutdownRegistry interface {
+	Flush(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+// RangeRegistry is implemented by registries which create percentile meters that clamp the recorded
+// values into a range. It is optional, so callers should check for it with a type assertion.
+type RangeRegistry interface {
+	PercentileDistributionSummaryWithRange(name string, tags map[string]string, min int64, max int64) *meter.PercentileDistributionSummary
+	PercentileDistributionSummaryWithIdWithRange(id *meter.Id, min int64, max int64) *meter.PercentileDistributionSummary
+	PercentileTimerWithRange(name string, tags map[string]string, min time.Duration, max time.Duration) *meter.PercentileTimer
+	PercentileTimerWithIdWithRange(id *meter.Id, min time.Duration, max time.Duration) *meter.PercentileTimer
+}
+
+// Used to validate that spectatordRegistry implements Registry, and the optional interfaces, at build time.
+var (
+	_ Registry               = (*spectatordRegistry)(nil)
+	_ StatsRegistry          = (*spectatordRegistry)(nil)
+	_ ScopedRegistry         = (*spectatordRegistry)(nil)
+	_ ReconfigurableRegistry = (*spectatordRegistry)(nil)
+	_ ShutdownRegistry       = (*spectatordRegistry)(nil)
+	_ RangeRegistry          = (*spectatordRegistry)(nil)
+)
+
+// registryIds assigns the ids of the registries, and of their views.
+var registryIds atomic.Uint64
 
 type spectatordRegistry struct {
-	config *Config
-	writer writer.Writer
-	logger logger.Logger
+	// id identifies the registry, or the view, in the caches of the meter definitions.
+	id uint64
+	// state is shared with the views created through Scoped.
+	state *registryState
+	// prefix and scopedTags are set on the views created through Scoped.
+	prefix     string
+	scopedTags map[string]string
+	scoped     bool
+}
+
+// registryState holds the configuration and the writer, which are replaced by Reconfigure.
+type registryState struct {
+	config atomic.Pointer[Config]
+	writer *swapWriter
+	// replacedDrops are the lines dropped by the writers replaced by Reconfigure.
+	replacedDrops atomic.Uint64
+
+	// flush is the flush in progress, if any, which is shared by the concurrent calls to Flush.
+	flushMu sync.Mutex
+	flush   *flushCall
+
+	// shutdownDone is closed once the writer is closed, and shutdownErr holds the result.
+	shutdownOnce sync.Once
+	shutdownDone chan struct{}
+	shutdownErr  error
+}
+
+// flushCall is a flush of the writer, which completes when done is closed, with err.
+type flushCall struct {
+	done chan struct{}
+	err  error
+}
+
+// LinesLostError is returned by Shutdown, when lines were discarded, and will never be delivered. This
+// includes the lines dropped by the writer, and by the writers replaced by Reconfigure, such as buffer
+// overflows, and the lines written after the shutdown started.
+type LinesLostError struct {
+	Lines uint64
+}
+
+func (e *LinesLostError) Error() string {
+	return fmt.Sprintf("%d lines were lost", e.Lines)
 }
 
 // NewRegistry generates a new registry from a passed Config created through NewConfig.
@@ -69,134 +156,388 @@ func NewRegistry(config *Config) (Registry, error) {
 		config, _ = NewConfig("", nil, nil)
 	}
 
-	newWriter, err := writer.NewWriterWithBuffer(config.location, config.log, config.bufferSize, config.flushInterval)
+	newWriter, err := newConfiguredWriter(config)
 	if err != nil {
 		return nil, err
 	}
 
 	config.log.Infof("Create Registry with extraCommonTags=%v", config.extraCommonTags)
 
-	r := &spectatordRegistry{
-		config: config,
-		writer: newWriter,
-		logger: config.log,
+	return newRegistryWithWriter(config, newWriter), nil
+}
+
+// newConfiguredWriter creates the writer for the output location of the config, wrapped in a
+// SamplingWriter when sample rules are configured.
+func newConfiguredWriter(config *Config) (writer.Writer, error) {
+	w, err := writer.NewWriterWithBuffer(config.location, config.writerLogger(), config.bufferSize, config.flushInterval)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.sampleRules) > 0 {
+		return newSamplingWriter(w, config.sampleRules, config.extraCommonTags, sampleStatusInterval), nil
 	}
+	return w, nil
+}
 
-	return r, nil
+// newRegistryWithWriter creates a registry which owns the writer.
+func newRegistryWithWriter(config *Config, w writer.Writer) *spectatordRegistry {
+	state := &registryState{writer: newSwapWriter(w), shutdownDone: make(chan struct{})}
+	state.config.Store(config)
+	return &spectatordRegistry{id: registryIds.Add(1), state: state}
+}
+
+// config returns the current configuration.
+func (r *spectatordRegistry) config() *Config {
+	return r.state.config.Load()
 }
 
 // GetLogger returns the internal logger.
 func (r *spectatordRegistry) GetLogger() logger.Logger {
-	return r.logger
+	return r.config().log
 }
 
-// NewId calls meters.NewId() and adds the extraCommonTags registered in the config.
+// NewId calls meters.NewId() and adds the extraCommonTags registered in the config. If validation is
+// enabled in the config, then invalid Ids are reported through the logger.
+//
+// For a scoped registry, the name prefix is prepended to the name, and the scoped tags are added. The
+// tags passed to NewId take precedence over the scoped tags, and the extraCommonTags take precedence over
+// both.
 func (r *spectatordRegistry) NewId(name string, tags map[string]string) *meter.Id {
-	newId := meter.NewId(name, tags)
+	config := r.config()
+
+	var newId *meter.Id
+	if r.scoped {
+		newId = meter.NewId(r.prefix+name, r.scopedTags)
+		if len(tags) > 0 {
+			newId = newId.WithTags(tags)
+		}
+	} else {
+		newId = meter.NewId(name, tags)
+	}
 
-	if len(r.config.extraCommonTags) > 0 {
-		newId = newId.WithTags(r.config.extraCommonTags)
+	if len(config.extraCommonTags) > 0 {
+		newId = newId.WithTags(config.extraCommonTags)
+	}
+
+	if config.validation {
+		if err := newId.Validate(); err != nil {
+			config.log.Errorf("Invalid meter id: %v", err)
+		}
 	}
 
 	return newId
 }
 
+// noopWriter discards the updates of the meters denied by the meter filters.
+var noopWriter = &writer.NoopWriter{}
+
+// filter applies the meter filters of the config to the Id, and returns the Id and the writer for the
+// meter. The extra common tags are added once, by NewId, before the filters run, so the filters see the
+// complete Id, and the tag filters apply to the common tags too.
+func (r *spectatordRegistry) filter(id *meter.Id) (*meter.Id, writer.Writer) {
+	config := r.config()
+	if len(config.meterFilters) == 0 {
+		return id, r.state.writer
+	}
+
+	filtered, accepted := applyMeterFilters(config.meterFilters, id)
+	if !accepted {
+		return filtered, noopWriter
+	}
+	return filtered, r.state.writer
+}
+
 func (r *spectatordRegistry) AgeGauge(name string, tags map[string]string) *meter.AgeGauge {
-	return meter.NewAgeGauge(r.NewId(name, tags), r.writer)
+	return meter.NewAgeGauge(r.filter(r.NewId(name, tags)))
 }
 
 func (r *spectatordRegistry) AgeGaugeWithId(id *meter.Id) *meter.AgeGauge {
-	return meter.NewAgeGauge(id, r.writer)
+	return meter.NewAgeGauge(r.filter(id))
 }
 
 func (r *spectatordRegistry) Counter(name string, tags map[string]string) *meter.Counter {
-	return meter.NewCounter(r.NewId(name, tags), r.writer)
+	return meter.NewCounter(r.filter(r.NewId(name, tags)))
 }
 
 func (r *spectatordRegistry) CounterWithId(id *meter.Id) *meter.Counter {
-	return meter.NewCounter(id, r.writer)
+	return meter.NewCounter(r.filter(id))
 }
 
 func (r *spectatordRegistry) DistributionSummary(name string, tags map[string]string) *meter.DistributionSummary {
-	return meter.NewDistributionSummary(r.NewId(name, tags), r.writer)
+	return meter.NewDistributionSummary(r.filter(r.NewId(name, tags)))
 }
 
 func (r *spectatordRegistry) DistributionSummaryWithId(id *meter.Id) *meter.DistributionSummary {
-	return meter.NewDistributionSummary(id, r.writer)
+	return meter.NewDistributionSummary(r.filter(id))
 }
 
 func (r *spectatordRegistry) Gauge(name string, tags map[string]string) *meter.Gauge {
-	return meter.NewGauge(r.NewId(name, tags), r.writer)
+	return meter.NewGauge(r.filter(r.NewId(name, tags)))
 }
 
 func (r *spectatordRegistry) GaugeWithId(id *meter.Id) *meter.Gauge {
-	return meter.NewGauge(id, r.writer)
+	return meter.NewGauge(r.filter(id))
 }
 
 func (r *spectatordRegistry) GaugeWithTTL(name string, tags map[string]string, duration time.Duration) *meter.Gauge {
-	return meter.NewGaugeWithTTL(r.NewId(name, tags), r.writer, duration)
+	id, w := r.filter(r.NewId(name, tags))
+	return meter.NewGaugeWithTTL(id, w, duration)
 }
 
 func (r *spectatordRegistry) GaugeWithIdWithTTL(id *meter.Id, duration time.Duration) *meter.Gauge {
-	return meter.NewGaugeWithTTL(id, r.writer, duration)
+	id, w := r.filter(id)
+	return meter.NewGaugeWithTTL(id, w, duration)
 }
 
 func (r *spectatordRegistry) MaxGauge(name string, tags map[string]string) *meter.MaxGauge {
-	return meter.NewMaxGauge(r.NewId(name, tags), r.writer)
+	return meter.NewMaxGauge(r.filter(r.NewId(name, tags)))
 }
 
 func (r *spectatordRegistry) MaxGaugeWithId(id *meter.Id) *meter.MaxGauge {
-	return meter.NewMaxGauge(id, r.writer)
+	return meter.NewMaxGauge(r.filter(id))
 }
 
 func (r *spectatordRegistry) MonotonicCounter(name string, tags map[string]string) *meter.MonotonicCounter {
-	return meter.NewMonotonicCounter(r.NewId(name, tags), r.writer)
+	return meter.NewMonotonicCounter(r.filter(r.NewId(name, tags)))
 }
 
 func (r *spectatordRegistry) MonotonicCounterWithId(id *meter.Id) *meter.MonotonicCounter {
-	return meter.NewMonotonicCounter(id, r.writer)
+	return meter.NewMonotonicCounter(r.filter(id))
 }
 
 func (r *spectatordRegistry) MonotonicCounterUint(name string, tags map[string]string) *meter.MonotonicCounterUint {
-	return meter.NewMonotonicCounterUint(r.NewId(name, tags), r.writer)
+	return meter.NewMonotonicCounterUint(r.filter(r.NewId(name, tags)))
 }
 
 func (r *spectatordRegistry) MonotonicCounterUintWithId(id *meter.Id) *meter.MonotonicCounterUint {
-	return meter.NewMonotonicCounterUint(id, r.writer)
+	return meter.NewMonotonicCounterUint(r.filter(id))
 }
 
 func (r *spectatordRegistry) PercentileDistributionSummary(name string, tags map[string]string) *meter.PercentileDistributionSummary {
-	return meter.NewPercentileDistributionSummary(r.NewId(name, tags), r.writer)
+	return meter.NewPercentileDistributionSummary(r.filter(r.NewId(name, tags)))
 }
 
 func (r *spectatordRegistry) PercentileDistributionSummaryWithId(id *meter.Id) *meter.PercentileDistributionSummary {
-	return meter.NewPercentileDistributionSummary(id, r.writer)
+	return meter.NewPercentileDistributionSummary(r.filter(id))
+}
+
+func (r *spectatordRegistry) PercentileDistributionSummaryWithRange(name string, tags map[string]string, min int64, max int64) *meter.PercentileDistributionSummary {
+	id, w := r.filter(r.NewId(name, tags))
+	return meter.NewPercentileDistributionSummaryWithRange(id, w, min, max)
+}
+
+func (r *spectatordRegistry) PercentileDistributionSummaryWithIdWithRange(id *meter.Id, min int64, max int64) *meter.PercentileDistributionSummary {
+	id, w := r.filter(id)
+	return meter.NewPercentileDistributionSummaryWithRange(id, w, min, max)
 }
 
 func (r *spectatordRegistry) PercentileTimer(name string, tags map[string]string) *meter.PercentileTimer {
-	return meter.NewPercentileTimer(r.NewId(name, tags), r.writer)
+	return meter.NewPercentileTimer(r.filter(r.NewId(name, tags)))
 }
 
 func (r *spectatordRegistry) PercentileTimerWithId(id *meter.Id) *meter.PercentileTimer {
-	return meter.NewPercentileTimer(id, r.writer)
+	return meter.NewPercentileTimer(r.filter(id))
+}
+
+func (r *spectatordRegistry) PercentileTimerWithRange(name string, tags map[string]string, min time.Duration, max time.Duration) *meter.PercentileTimer {
+	id, w := r.filter(r.NewId(name, tags))
+	return meter.NewPercentileTimerWithRange(id, w, min, max)
+}
+
+func (r *spectatordRegistry) PercentileTimerWithIdWithRange(id *meter.Id, min time.Duration, max time.Duration) *meter.PercentileTimer {
+	id, w := r.filter(id)
+	return meter.NewPercentileTimerWithRange(id, w, min, max)
 }
 
 func (r *spectatordRegistry) Timer(name string, tags map[string]string) *meter.Timer {
-	return meter.NewTimer(r.NewId(name, tags), r.writer)
+	return meter.NewTimer(r.filter(r.NewId(name, tags)))
 }
 
 func (r *spectatordRegistry) TimerWithId(id *meter.Id) *meter.Timer {
-	return meter.NewTimer(id, r.writer)
+	return meter.NewTimer(r.filter(id))
 }
 
+// GetWriter returns the writer of the current configuration. Reconfigure replaces it, and closes the
+// previous one, so callers should not keep it across a Reconfigure.
 func (r *spectatordRegistry) GetWriter() writer.Writer {
-	return r.writer
+	return r.state.writer.current()
 }
 
-func (r *spectatordRegistry) Close() {
-	r.GetLogger().Infof("Close Registry Writer")
-	err := r.writer.Close()
+// GetWriterStats returns the delivery statistics of the writer, and reports whether the writer tracks
+// them. Health checks can use these statistics to detect when metrics delivery is degraded.
+func (r *spectatordRegistry) GetWriterStats() (writer.Stats, bool) {
+	if sw, ok := r.state.writer.current().(writer.StatsWriter); ok {
+		return sw.Stats(), true
+	}
+	return writer.Stats{}, false
+}
+
+// Scoped returns a view of the registry, for libraries that publish metrics under their own namespace. The
+// meters created through the view have names starting with `prefix.`, and carry the scoped tags, in
+// addition to the extraCommonTags. Scoping a scoped registry nests the prefixes, and merges the tags.
+//
+// The view shares the writer of the registry. Closing the view does nothing, and the writer is closed
+// when the registry that owns it is closed. The meters created through the *WithId methods use the Id
+// as is, so build those Ids with the NewId method of the view.
+func (r *spectatordRegistry) Scoped(prefix string, tags map[string]string) Registry {
+	scopedTags := make(map[string]string, len(r.scopedTags)+len(tags))
+	for k, v := range r.scopedTags {
+		scopedTags[k] = v
+	}
+	for k, v := range tags {
+		scopedTags[k] = v
+	}
+
+	if prefix != "" {
+		prefix += "."
+	}
+
+	return &spectatordRegistry{
+		id:         registryIds.Add(1),
+		state:      r.state,
+		prefix:     r.prefix + prefix,
+		scopedTags: scopedTags,
+		scoped:     true,
+	}
+}
+
+// Reconfigure applies a new configuration to the registry, and to the views created through Scoped. A new
+// writer is created for the output location, buffer size and flush interval, and the meters that already
+// exist are switched over to it. The writes in flight on the previous writer complete before it is closed,
+// which flushes its buffers, so no write is dropped by the swap. If the new writer cannot be created, the
+// error is returned, and the registry keeps its current configuration.
+//
+// The common tags, meter filters, validation and logger of the new configuration apply to the meters
+// created afterwards. The Ids of existing meters are not changed.
+func (r *spectatordRegistry) Reconfigure(config *Config) error {
+	if config == nil {
+		return fmt.Errorf("Config cannot be nil")
+	}
+
+	if config.location == "" {
+		// Config was not created using NewConfig. Set a default config instead of using the passed one.
+		config, _ = NewConfig("", nil, nil)
+	}
+
+	newWriter, err := newConfiguredWriter(config)
+	if err != nil {
+		return err
+	}
+
+	config.log.Infof("Reconfigure Registry with location=%s, extraCommonTags=%v", config.location, config.extraCommonTags)
+
+	old, ok := r.state.writer.swap(newWriter)
+	if !ok {
+		_ = newWriter.Close()
+		return fmt.Errorf("Registry is shut down")
+	}
+	oldConfig := r.config()
+	r.state.config.Store(config)
+	if err := old.Close(); err != nil {
+		config.log.Errorf("Error closing previous Registry Writer: %v", err)
+	}
+	if sw, ok := old.(writer.StatsWriter); ok {
+		r.state.replacedDrops.Add(sw.Stats().Drops)
+	}
+	if oldConfig != config {
+		oldConfig.stopWriterLogger()
+	}
+	return nil
+}
+
+// Flush delivers the lines held by the buffers of the writer, if any. It returns the error of the context,
+// if the flush does not complete before the context is done. In that case, the flush continues in the
+// background.
+//
+// At most one flush is in progress at a time: if a flush is already in progress, such as one which outlived
+// the context of a previous call, then Flush waits for it, instead of starting another one.
+func (r *spectatordRegistry) Flush(ctx context.Context) error {
+	flusher, ok := r.state.writer.current().(writer.Flusher)
+	if !ok {
+		return nil
+	}
+
+	state := r.state
+	state.flushMu.Lock()
+	call := state.flush
+	if call == nil {
+		call = &flushCall{done: make(chan struct{})}
+		state.flush = call
+		go func() {
+			call.err = flusher.Flush()
+			state.flushMu.Lock()
+			state.flush = nil
+			state.flushMu.Unlock()
+			close(call.done)
+		}()
+	}
+	state.flushMu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return fmt.Errorf("Registry flush did not complete: %w", ctx.Err())
+	}
+}
+
+// Shutdown flushes the buffers, and closes the writer. The writes made afterwards, by any meter, are
+// discarded. It returns the error of the context, if the shutdown does not complete before the context
+// is done, in which case the shutdown continues in the background. If lines were lost, then the error
+// includes a *LinesLostError.
+//
+// Shutdown is safe to call more than once, and the later calls wait for the first one to complete. For a
+// view created through Scoped, Shutdown does nothing, because the writer is owned by the parent.
+func (r *spectatordRegistry) Shutdown(ctx context.Context) error {
+	if r.scoped {
+		r.GetLogger().Debugf("Ignore Shutdown of scoped Registry, the writer is owned by the parent Registry")
+		return nil
+	}
+
+	r.state.shutdownOnce.Do(func() {
+		go func() {
+			r.state.shutdownErr = r.closeWriter()
+			close(r.state.shutdownDone)
+		}()
+	})
+
+	select {
+	case <-r.state.shutdownDone:
+		return r.state.shutdownErr
+	case <-ctx.Done():
+		return fmt.Errorf("Registry shutdown did not complete: %w", ctx.Err())
+	}
+}
+
+// closeWriter closes the writer, and reports the lines that were lost.
+func (r *spectatordRegistry) closeWriter() error {
+	log := r.GetLogger()
+	log.Infof("Close Registry Writer")
+
+	w := r.state.writer.current()
+	err := r.state.writer.Close()
 	if err != nil {
-		r.GetLogger().Errorf("Error closing Registry Writer: %v", err)
+		log.Errorf("Error closing Registry Writer: %v", err)
+	}
+
+	r.config().stopWriterLogger()
+
+	lost := r.state.writer.discarded.Load() + r.state.replacedDrops.Load()
+	if sw, ok := w.(writer.StatsWriter); ok {
+		lost += sw.Stats().Drops
+	}
+	if lost > 0 {
+		log.Errorf("Registry Writer lost %d lines", lost)
+		err = errors.Join(err, &LinesLostError{Lines: lost})
+	}
+	return err
+}
+
+// Close shuts down the registry, without a deadline, and logs any error. See Shutdown.
+func (r *spectatordRegistry) Close() {
+	if r.scoped {
+		r.GetLogger().Debugf("Ignore Close of scoped Registry, the writer is owned by the parent Registry")
+		return
 	}
+	_ = r.Shutdown(context.Background())
 }
@@ -1,9 +1,16 @@
 package spectator
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/Netflix/spectator-go/v2/spectator/logger"
 	"github.com/Netflix/spectator-go/v2/spectator/writer"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -282,6 +289,70 @@ func TestRegistryWithMemoryWriter_PercentileTimerWithId(t *testing.T) {
 	}
 }
 
+func TestRegistryWithMemoryWriter_PercentileDistributionSummaryWithRange(t *testing.T) {
+	r := NewTestRegistry()
+	mw := r.GetWriter().(*writer.MemoryWriter)
+
+	percentileDistSummary := r.(RangeRegistry).PercentileDistributionSummaryWithRange("test_percentiledistributionsummary", nil, 10, 1000)
+	percentileDistSummary.Record(5)
+	percentileDistSummary.Record(400)
+	percentileDistSummary.Record(5000)
+
+	expected := []string{
+		"D:test_percentiledistributionsummary:10",
+		"D:test_percentiledistributionsummary:400",
+		"D:test_percentiledistributionsummary:1000",
+	}
+	if !reflect.DeepEqual(mw.Lines(), expected) {
+		t.Errorf("Expected %v, got %v", expected, mw.Lines())
+	}
+}
+
+func TestRegistryWithMemoryWriter_PercentileDistributionSummaryWithIdWithRange(t *testing.T) {
+	r := NewTestRegistryWithCommonTags()
+	mw := r.GetWriter().(*writer.MemoryWriter)
+
+	percentileDistSummary := r.(RangeRegistry).PercentileDistributionSummaryWithIdWithRange(r.NewId("test_percentiledistributionsummary", nil), 10, 1000)
+	percentileDistSummary.Record(5000)
+
+	expected := "D:test_percentiledistributionsummary,extra-tag=foo:1000"
+	if len(mw.Lines()) != 1 || mw.Lines()[0] != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, mw.Lines())
+	}
+}
+
+func TestRegistryWithMemoryWriter_PercentileTimerWithRange(t *testing.T) {
+	r := NewTestRegistry()
+	mw := r.GetWriter().(*writer.MemoryWriter)
+
+	percentileTimer := r.(RangeRegistry).PercentileTimerWithRange("test_percentiletimer", nil, 10*time.Millisecond, time.Second)
+	percentileTimer.Record(time.Millisecond)
+	percentileTimer.Record(500 * time.Millisecond)
+	percentileTimer.Record(time.Minute)
+
+	expected := []string{
+		"T:test_percentiletimer:0.010000",
+		"T:test_percentiletimer:0.500000",
+		"T:test_percentiletimer:1.000000",
+	}
+	if !reflect.DeepEqual(mw.Lines(), expected) {
+		t.Errorf("Expected %v, got %v", expected, mw.Lines())
+	}
+}
+
+func TestRegistryWithMemoryWriter_PercentileTimerWithIdWithRange(t *testing.T) {
+	r := NewTestRegistryWithCommonTags()
+	mw := r.GetWriter().(*writer.MemoryWriter)
+
+	percentileTimer := r.(RangeRegistry).PercentileTimerWithIdWithRange(r.NewId("test_percentiletimer", nil), 10*time.Millisecond, time.Second)
+	percentileTimer.Record(time.Millisecond)
+
+	expected := "T:test_percentiletimer,extra-tag=foo:0.010000"
+	if len(mw.Lines()) != 1 || mw.Lines()[0] != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, mw.Lines())
+	}
+}
+
 func TestRegistryWithMemoryWriter_Timer(t *testing.T) {
 	r := NewTestRegistry()
 	mw := r.GetWriter().(*writer.MemoryWriter)
@@ -323,3 +394,328 @@ func TestNewRegistryWithNilConfig(t *testing.T) {
 		t.Errorf("Registry should return an error for nil config, got nil")
 	}
 }
+
+func TestRegistryWithMemoryWriter_GetWriterStats(t *testing.T) {
+	r := NewTestRegistry()
+
+	r.Counter("test_counter", nil).Increment()
+	r.Counter("test_counter", nil).Increment()
+
+	stats, ok := r.(StatsRegistry).GetWriterStats()
+	if !ok {
+		t.Fatalf("Expected MemoryWriter to track stats")
+	}
+	if stats.LinesWritten != 2 {
+		t.Errorf("Expected 2 lines written, got %d", stats.LinesWritten)
+	}
+	if stats.BytesWritten != uint64(2*len("c:test_counter:1")) {
+		t.Errorf("Expected %d bytes written, got %d", 2*len("c:test_counter:1"), stats.BytesWritten)
+	}
+}
+
+func TestRegistryWithNoopWriter_GetWriterStats(t *testing.T) {
+	config, _ := NewConfig("none", nil, logger.NewDefaultLogger())
+	r, _ := NewRegistry(config)
+
+	if _, ok := r.(StatsRegistry).GetWriterStats(); ok {
+		t.Errorf("Expected NoopWriter not to track stats")
+	}
+}
+
+func TestRegistry_Scoped(t *testing.T) {
+	r := NewTestRegistryWithCommonTags()
+	mw := r.GetWriter().(*writer.MemoryWriter)
+
+	scoped := r.(ScopedRegistry).Scoped("lib", map[string]string{"lib.version": "1", "extra-tag": "scoped"})
+	scoped.Counter("requests", map[string]string{"status": "200"}).Increment()
+
+	id := scoped.NewId("requests", map[string]string{"status": "200"})
+	expectedTags := map[string]string{"lib.version": "1", "status": "200", "extra-tag": "foo"}
+	if id.Name() != "lib.requests" || fmt.Sprint(id.Tags()) != fmt.Sprint(expectedTags) {
+		t.Errorf("Expected lib.requests with tags %v, got %s with tags %v", expectedTags, id.Name(), id.Tags())
+	}
+
+	if len(mw.Lines()) != 1 {
+		t.Fatalf("Expected one line, got %v", mw.Lines())
+	}
+	_, written, value, err := ParseProtocolLine(mw.Lines()[0])
+	if err != nil || written.MapKey() != id.MapKey() || value != "1" {
+		t.Errorf("Expected a counter line for %s, got '%s'", id.MapKey(), mw.Lines()[0])
+	}
+}
+
+func TestRegistry_ScopedNested(t *testing.T) {
+	r := NewTestRegistry()
+
+	nested := r.(ScopedRegistry).Scoped("lib", map[string]string{"a": "1", "b": "1"}).(ScopedRegistry).Scoped("client", map[string]string{"b": "2"})
+	id := nested.NewId("calls", map[string]string{"a": "3"})
+
+	expectedTags := map[string]string{"a": "3", "b": "2"}
+	if id.Name() != "lib.client.calls" || fmt.Sprint(id.Tags()) != fmt.Sprint(expectedTags) {
+		t.Errorf("Expected lib.client.calls with tags %v, got %s with tags %v", expectedTags, id.Name(), id.Tags())
+	}
+
+	if id := r.(ScopedRegistry).Scoped("", nil).NewId("calls", nil); id.Name() != "calls" {
+		t.Errorf("Expected calls, got %s", id.Name())
+	}
+}
+
+func TestRegistry_ScopedCloseDoesNotCloseParent(t *testing.T) {
+	path := t.TempDir() + "/metrics.log"
+	config, _ := NewConfig("file://"+path, nil, nil)
+	r, _ := NewRegistry(config)
+	defer r.Close()
+
+	scoped := r.(ScopedRegistry).Scoped("lib", nil)
+	scoped.Close()
+
+	r.Counter("after_close", nil).Increment()
+	if stats, _ := r.(StatsRegistry).GetWriterStats(); stats.WriteErrors != 0 || stats.LinesWritten != 1 {
+		t.Errorf("Expected the parent writer to remain open, got %+v", stats)
+	}
+}
+
+func TestRegistry_Reconfigure(t *testing.T) {
+	r := NewTestRegistry()
+	scoped := r.(ScopedRegistry).Scoped("lib", nil)
+	counter := r.Counter("before", nil)
+
+	config, _ := NewConfig("memory", map[string]string{"extra-tag": "new"}, nil)
+	if err := r.(ReconfigurableRegistry).Reconfigure(config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mw := r.GetWriter().(*writer.MemoryWriter)
+
+	counter.Increment()
+	r.Counter("after", nil).Increment()
+	scoped.Counter("after", nil).Increment()
+
+	expected := []string{"c:before:1", "c:after,extra-tag=new:1", "c:lib.after,extra-tag=new:1"}
+	if fmt.Sprint(mw.Lines()) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, mw.Lines())
+	}
+	if scoped.GetWriter() != mw {
+		t.Errorf("Expected the scoped registry to share the new writer")
+	}
+}
+
+func TestRegistry_GetWriterAfterReconfigure(t *testing.T) {
+	r := NewTestRegistry()
+	first := r.GetWriter()
+
+	config, _ := NewConfig("memory", nil, nil)
+	if err := r.(ReconfigurableRegistry).Reconfigure(config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mw, ok := r.GetWriter().(*writer.MemoryWriter)
+	if !ok || mw == first {
+		t.Fatalf("Expected GetWriter to return the new MemoryWriter, got %T", r.GetWriter())
+	}
+
+	r.Counter("test_counter", nil).Increment()
+	if fmt.Sprint(mw.Lines()) != "[c:test_counter:1]" {
+		t.Errorf("Expected the meters to write to the new writer, got %v", mw.Lines())
+	}
+}
+
+func TestRegistry_ReconfigureInvalid(t *testing.T) {
+	r := NewTestRegistry()
+	mw := r.GetWriter()
+
+	if err := r.(ReconfigurableRegistry).Reconfigure(nil); err == nil {
+		t.Errorf("Expected error for nil config")
+	}
+	config, _ := NewConfig("file://"+t.TempDir()+"/missing/metrics.log", nil, nil)
+	if err := r.(ReconfigurableRegistry).Reconfigure(config); err == nil {
+		t.Errorf("Expected error for a file in a missing directory")
+	}
+	if r.GetWriter() != mw {
+		t.Errorf("Expected the writer to be unchanged")
+	}
+}
+
+func TestRegistry_ReconfigureDoesNotDropWrites(t *testing.T) {
+	dir := t.TempDir()
+	config, _ := NewConfig("file://"+dir+"/first.log?bufferSize=4096&flushInterval=1h", nil, nil)
+	r, _ := NewRegistry(config)
+	counter := r.Counter("test_counter", nil)
+
+	const goroutines, writes = 4, 2000
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < writes; j++ {
+				counter.Increment()
+			}
+		}()
+	}
+
+	config, _ = NewConfig("file://"+dir+"/second.log?bufferSize=4096&flushInterval=1h", nil, nil)
+	if err := r.(ReconfigurableRegistry).Reconfigure(config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wg.Wait()
+	r.Close()
+
+	lines := 0
+	for _, name := range []string{"first.log", "second.log"} {
+		data, err := os.ReadFile(dir + "/" + name)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		lines += strings.Count(string(data), "\n")
+	}
+	if lines != goroutines*writes {
+		t.Errorf("Expected %d lines, got %d", goroutines*writes, lines)
+	}
+}
+
+// slowWriter blocks Close until release is closed, and reports a fixed number of drops.
+type slowWriter struct {
+	writer.MemoryWriter
+	release chan struct{}
+	drops   uint64
+}
+
+func (s *slowWriter) Close() error {
+	<-s.release
+	return nil
+}
+
+func (s *slowWriter) Stats() writer.Stats {
+	return writer.Stats{Drops: s.drops}
+}
+
+func TestRegistry_Flush(t *testing.T) {
+	path := t.TempDir() + "/metrics.log"
+	config, _ := NewConfig("file://"+path+"?bufferSize=4096&flushInterval=1h", nil, nil)
+	r, _ := NewRegistry(config)
+	defer r.Close()
+
+	r.Counter("test_counter", nil).Increment()
+	if err := r.(ShutdownRegistry).Flush(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "c:test_counter:1\n" {
+		t.Errorf("Expected the counter line after flush, got '%s'", data)
+	}
+
+	// writers without buffers have nothing to flush
+	if err := NewTestRegistry().(ShutdownRegistry).Flush(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// stuckFlusher blocks Flush until release is closed, and counts the flushes.
+type stuckFlusher struct {
+	writer.MemoryWriter
+	release chan struct{}
+	flushes atomic.Int32
+}
+
+func (s *stuckFlusher) Flush() error {
+	s.flushes.Add(1)
+	<-s.release
+	return nil
+}
+
+func TestRegistry_FlushSingleInFlight(t *testing.T) {
+	w := &stuckFlusher{release: make(chan struct{})}
+	config, _ := NewConfig("memory", nil, nil)
+	r := newRegistryWithWriter(config, w)
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		if err := r.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded, got %v", err)
+		}
+		cancel()
+	}
+	if n := w.flushes.Load(); n != 1 {
+		t.Errorf("Expected a single flush in progress, got %d", n)
+	}
+
+	close(w.release)
+	if err := r.Flush(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestRegistry_Shutdown(t *testing.T) {
+	r := NewTestRegistry()
+	mw := r.GetWriter().(*writer.MemoryWriter)
+	counter := r.Counter("test_counter", nil)
+	counter.Increment()
+
+	if err := r.(ShutdownRegistry).Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := r.(ShutdownRegistry).Shutdown(context.Background()); err != nil {
+		t.Errorf("Unexpected error on second shutdown: %v", err)
+	}
+	r.Close()
+
+	counter.Increment()
+	r.Counter("after_shutdown", nil).Increment()
+	if len(mw.Lines()) != 1 {
+		t.Errorf("Expected the writes after shutdown to be discarded, got %v", mw.Lines())
+	}
+
+	config, _ := NewConfig("memory", nil, nil)
+	if err := r.(ReconfigurableRegistry).Reconfigure(config); err == nil {
+		t.Errorf("Expected error when reconfiguring a registry that is shut down")
+	}
+}
+
+func TestRegistry_ShutdownDeadline(t *testing.T) {
+	w := &slowWriter{release: make(chan struct{}), drops: 3}
+	config, _ := NewConfig("memory", nil, nil)
+	r := newRegistryWithWriter(config, w)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := r.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+
+	close(w.release)
+	err := r.Shutdown(context.Background())
+	var lost *LinesLostError
+	if !errors.As(err, &lost) || lost.Lines != 3 {
+		t.Errorf("Expected 3 lines lost, got %v", err)
+	}
+}
+
+func TestRegistry_ShutdownCountsReplacedWriterDrops(t *testing.T) {
+	w := &slowWriter{release: make(chan struct{}), drops: 3}
+	close(w.release)
+	config, _ := NewConfig("memory", nil, nil)
+	r := newRegistryWithWriter(config, w)
+
+	if err := r.Reconfigure(config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	err := r.Shutdown(context.Background())
+	var lost *LinesLostError
+	if !errors.As(err, &lost) || lost.Lines != 3 {
+		t.Errorf("Expected the 3 lines lost by the replaced writer, got %v", err)
+	}
+}
+
+func TestRegistry_ScopedShutdownDoesNotCloseParent(t *testing.T) {
+	r := NewTestRegistry()
+	mw := r.GetWriter().(*writer.MemoryWriter)
+
+	if err := r.(ScopedRegistry).Scoped("lib", nil).(ShutdownRegistry).Shutdown(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	r.Counter("test_counter", nil).Increment()
+	if len(mw.Lines()) != 1 {
+		t.Errorf("Expected the parent registry to remain open, got %v", mw.Lines())
+	}
+}
@@ -0,0 +1,163 @@
+package spectator
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/Netflix/spectator-go/v2/spectator/meter"
+	"github.com/Netflix/spectator-go/v2/spectator/writer"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ReplayOptions configures how a recorded protocol file is sent to an output location. Recorded files do
+// not carry timestamps, so a replay does not reproduce the original timing, and is paced by LinesPerSecond.
+type ReplayOptions struct {
+	// LinesPerSecond limits the rate at which lines are written. Zero disables rate limiting.
+	LinesPerSecond float64
+	// SetTags adds tags to every line, replacing any recorded values for the same keys.
+	SetTags map[string]string
+	// DropTags removes tags from every line.
+	DropTags []string
+}
+
+// validate reports the invalid options.
+func (o ReplayOptions) validate() error {
+	if o.LinesPerSecond < 0 {
+		return fmt.Errorf("replay rate %v cannot be negative", o.LinesPerSecond)
+	}
+	return nil
+}
+
+// ReplayResult reports the outcome of a replay.
+type ReplayResult struct {
+	// Lines is the number of lines written to the output.
+	Lines int
+	// Invalid is the number of lines skipped, because they could not be parsed.
+	Invalid int
+}
+
+// Replay reads spectatord protocol lines from r, as written by the FileWriter, validates each one with
+// ParseProtocolLine, applies the tag rewrites, and writes the result to w. Invalid lines are skipped.
+// It returns early, with the context error, if the context is cancelled.
+func Replay(ctx context.Context, r io.Reader, w writer.Writer, options ReplayOptions) (ReplayResult, error) {
+	var result ReplayResult
+	if err := options.validate(); err != nil {
+		return result, err
+	}
+
+	var interval time.Duration
+	if options.LinesPerSecond > 0 {
+		interval = time.Duration(float64(time.Second) / options.LinesPerSecond)
+	}
+
+	start := time.Now()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		rewritten, err := rewriteProtocolLine(line, options)
+		if err != nil {
+			result.Invalid++
+			continue
+		}
+
+		if interval > 0 {
+			next := start.Add(time.Duration(result.Lines) * interval)
+			if delay := time.Until(next); delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return result, ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		w.Write(rewritten)
+		result.Lines++
+	}
+
+	return result, scanner.Err()
+}
+
+// ReplayFile replays a file written by the FileWriter to the output location of the config, which may be
+// any location accepted by writer.NewWriterWithBuffer, with the buffer settings of the config. Files with
+// a `.gz` suffix, such as compressed rotated files, are decompressed. The writer is closed before
+// returning, so that buffered lines are flushed.
+func ReplayFile(ctx context.Context, path string, config *Config, options ReplayOptions) (ReplayResult, error) {
+	if config == nil {
+		return ReplayResult{}, fmt.Errorf("Config cannot be nil")
+	}
+
+	if config.location == "" {
+		// Config was not created using NewConfig. Use a default config instead of the passed one.
+		config, _ = NewConfig("", nil, nil)
+	}
+
+	if err := options.validate(); err != nil {
+		return ReplayResult{}, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return ReplayResult{}, err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			return ReplayResult{}, err
+		}
+		defer zr.Close()
+		reader = zr
+	}
+
+	w, err := writer.NewWriterWithBuffer(config.location, config.writerLogger(), config.bufferSize, config.flushInterval)
+	if err != nil {
+		return ReplayResult{}, err
+	}
+
+	result, err := Replay(ctx, reader, w, options)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	return result, err
+}
+
+// rewriteProtocolLine validates the line, and applies the tag rewrites from the options. Lines are
+// returned unchanged when there are no rewrites.
+func rewriteProtocolLine(line string, options ReplayOptions) (string, error) {
+	symbol, id, value, err := ParseProtocolLine(line)
+	if err != nil {
+		return "", err
+	}
+
+	if len(options.SetTags) == 0 && len(options.DropTags) == 0 {
+		return line, nil
+	}
+
+	tags := id.Tags()
+	for _, k := range options.DropTags {
+		delete(tags, k)
+	}
+	for k, v := range options.SetTags {
+		tags[k] = v
+	}
+
+	id = meter.NewId(id.Name(), tags)
+	return symbol + ":" + id.SpectatordId() + ":" + value, nil
+}
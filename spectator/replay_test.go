@@ -0,0 +1,119 @@
+package spectator
+
+import (
+	"compress/gzip"
+	"context"
+	"github.com/Netflix/spectator-go/v2/spectator/logger"
+	"github.com/Netflix/spectator-go/v2/spectator/writer"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplay_SkipsInvalidLines(t *testing.T) {
+	mw := &writer.MemoryWriter{}
+	input := "c:counter:1\ninvalid\n\ng,60:gauge,a=b:2.000000\n"
+
+	result, err := Replay(context.Background(), strings.NewReader(input), mw, ReplayOptions{})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if result.Lines != 2 || result.Invalid != 1 {
+		t.Errorf("Expected 2 lines and 1 invalid, got %+v", result)
+	}
+
+	expected := []string{"c:counter:1", "g,60:gauge,a=b:2.000000"}
+	lines := mw.Lines()
+	if len(lines) != len(expected) || lines[0] != expected[0] || lines[1] != expected[1] {
+		t.Errorf("Expected %v, got %v", expected, lines)
+	}
+}
+
+func TestReplay_RewritesTags(t *testing.T) {
+	mw := &writer.MemoryWriter{}
+	input := "t:timer,nf.app=prod,nf.node=i-1234:0.100000\n"
+	options := ReplayOptions{
+		SetTags:  map[string]string{"nf.app": "replay"},
+		DropTags: []string{"nf.node"},
+	}
+
+	_, err := Replay(context.Background(), strings.NewReader(input), mw, options)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	expected := "t:timer,nf.app=replay:0.100000"
+	if len(mw.Lines()) != 1 || mw.Lines()[0] != expected {
+		t.Errorf("Expected '%s', got %v", expected, mw.Lines())
+	}
+}
+
+func TestReplay_RateLimit(t *testing.T) {
+	mw := &writer.MemoryWriter{}
+	input := strings.Repeat("c:counter:1\n", 5)
+
+	// 200 lines/sec is one line per 5ms, so the fifth line is written after 20ms
+	start := time.Now()
+	result, _ := Replay(context.Background(), strings.NewReader(input), mw, ReplayOptions{LinesPerSecond: 200})
+	elapsed := time.Since(start)
+
+	if result.Lines != 5 {
+		t.Errorf("Expected 5 lines, got %d", result.Lines)
+	}
+	if elapsed < 20*time.Millisecond {
+		t.Errorf("Expected replay to take at least 20ms, took %v", elapsed)
+	}
+}
+
+func TestReplay_NegativeRate(t *testing.T) {
+	mw := &writer.MemoryWriter{}
+	_, err := Replay(context.Background(), strings.NewReader("c:a:1\n"), mw, ReplayOptions{LinesPerSecond: -1})
+	if err == nil {
+		t.Errorf("Expected an error for a negative rate")
+	}
+	if len(mw.Lines()) != 0 {
+		t.Errorf("Expected no lines to be written, got %v", mw.Lines())
+	}
+}
+
+func TestReplay_ContextCancelled(t *testing.T) {
+	mw := &writer.MemoryWriter{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Replay(ctx, strings.NewReader("c:counter:1\n"), mw, ReplayOptions{LinesPerSecond: 1})
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(mw.Lines()) != 0 {
+		t.Errorf("Expected no lines written, got %v", mw.Lines())
+	}
+}
+
+func TestReplayFile_Compressed(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "metrics.log.gz")
+	output := filepath.Join(dir, "replayed.log")
+
+	file, _ := os.Create(input)
+	zw := gzip.NewWriter(file)
+	_, _ = zw.Write([]byte("c:counter:1\nd:summary:42\n"))
+	_ = zw.Close()
+	_ = file.Close()
+
+	config, _ := NewConfig("file://"+output, nil, logger.NewDefaultLogger())
+	result, err := ReplayFile(context.Background(), input, config, ReplayOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Lines != 2 {
+		t.Errorf("Expected 2 lines, got %d", result.Lines)
+	}
+
+	content, _ := os.ReadFile(output)
+	if string(content) != "c:counter:1\nd:summary:42\n" {
+		t.Errorf("Unexpected replayed content '%s'", string(content))
+	}
+}
@@ -0,0 +1,235 @@
+package spectator
+
+import (
+	"fmt"
+	"github.com/Netflix/spectator-go/v2/spectator/meter"
+	"github.com/Netflix/spectator-go/v2/spectator/writer"
+	"math/rand"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sampleStatusInterval is the interval at which the SamplingWriter publishes the effective sample rates.
+const sampleStatusInterval = time.Minute
+
+// sampleRateName is the name of the gauge which reports the effective sample rate of each SampleRule.
+const sampleRateName = "spectator-go.samplingWriter.sampleRate"
+
+// maxSampleNames bounds the number of meter names whose matching rule is cached. The rules of the names
+// seen once the cache is full are evaluated on every write.
+const maxSampleNames = 10000
+
+// SampleRule sets the fraction of the updates written for the meters with a name matching the glob
+// pattern, as defined by path.Match. A Rate of 0.1 writes about 10% of the updates.
+type SampleRule struct {
+	Pattern string
+	Rate    float64
+}
+
+// sampleRule holds the counts of a SampleRule, to compute the effective sample rate.
+type sampleRule struct {
+	SampleRule
+	status *meter.Gauge
+	seen   atomic.Uint64
+	kept   atomic.Uint64
+}
+
+// noSampleRule marks the meter names which do not match any rule.
+const noSampleRule = -1
+
+// SamplingWriter writes a fraction of the updates of extremely hot meters, to reduce the load on the
+// writer, when even the LowLatencyBuffer drops lines. The records of timers and distribution summaries
+// are sampled, and the increments of counters are sampled and scaled by the inverse of the rate, so that
+// the rate of the counter is preserved. The other meters, such as gauges and monotonic counters, are
+// always written, because their values cannot be sampled. The first matching rule applies, and the meters
+// which match no rule are not sampled.
+//
+// Every minute, the effective sample rate of each rule, which is the fraction of the updates written
+// during the last minute, is published as the gauge `spectator-go.samplingWriter.sampleRate`, with the tag `pattern`.
+//
+// Sampling reduces the count of the timers and distribution summaries, so only use it for meters where
+// the distribution of the values matters more than their count.
+type SamplingWriter struct {
+	w     writer.Writer
+	rules []*sampleRule
+	// names caches the index of the rule matching each meter name, to avoid evaluating the patterns on
+	// every write, up to maxSampleNames names.
+	names      sync.Map
+	namesCount atomic.Int64
+	random     func() float64
+
+	closeOnce sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewSamplingWriter creates a *SamplingWriter, which writes to w. Rates are clamped into the range
+// [0, 1]. Closing the SamplingWriter closes w.
+func NewSamplingWriter(w writer.Writer, rules []SampleRule) *SamplingWriter {
+	return newSamplingWriter(w, rules, nil, sampleStatusInterval)
+}
+
+// newSamplingWriter creates the writer, with the common tags added to the status gauges.
+func newSamplingWriter(w writer.Writer, rules []SampleRule, commonTags map[string]string, interval time.Duration) *SamplingWriter {
+	s := &SamplingWriter{w: w, random: rand.Float64, done: make(chan struct{})}
+	for _, rule := range rules {
+		rule.Rate = max(0, min(1, rule.Rate))
+		tags := make(map[string]string, len(commonTags)+1)
+		for k, v := range commonTags {
+			tags[k] = v
+		}
+		tags["pattern"] = rule.Pattern
+		status := meter.NewGauge(meter.NewId(sampleRateName, tags), w)
+		s.rules = append(s.rules, &sampleRule{SampleRule: rule, status: status})
+	}
+
+	if len(s.rules) > 0 {
+		s.wg.Add(1)
+		go s.publishStatusLoop(interval)
+	}
+	return s
+}
+
+func (s *SamplingWriter) publishStatusLoop(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.publishStatus()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// publishStatus writes the effective sample rate of each rule, since the previous call. A rule without
+// updates reports its configured rate.
+func (s *SamplingWriter) publishStatus() {
+	for _, rule := range s.rules {
+		seen, kept := rule.seen.Swap(0), rule.kept.Swap(0)
+		rate := rule.Rate
+		if seen > 0 {
+			rate = float64(kept) / float64(seen)
+		}
+		rule.status.Set(rate)
+	}
+}
+
+// rule returns the rule matching the meter name, or nil.
+func (s *SamplingWriter) rule(name string) *sampleRule {
+	if i, ok := s.names.Load(name); ok {
+		if i.(int) == noSampleRule {
+			return nil
+		}
+		return s.rules[i.(int)]
+	}
+
+	index := noSampleRule
+	for i, rule := range s.rules {
+		if ok, _ := path.Match(rule.Pattern, name); ok {
+			index = i
+			break
+		}
+	}
+	if s.namesCount.Load() < maxSampleNames {
+		if _, loaded := s.names.LoadOrStore(strings.Clone(name), index); !loaded {
+			s.namesCount.Add(1)
+		}
+	}
+	if index == noSampleRule {
+		return nil
+	}
+	return s.rules[index]
+}
+
+// sample returns the line to write, which is scaled for counters, and reports false if the line is
+// dropped.
+func (s *SamplingWriter) sample(line string) (string, bool) {
+	symbol, rest, ok := strings.Cut(line, ":")
+	if !ok {
+		return line, true
+	}
+	switch symbol {
+	case "c", "d", "D", "t", "T":
+	default:
+		return line, true
+	}
+
+	end := strings.IndexAny(rest, ",:")
+	if end < 0 {
+		return line, true
+	}
+	rule := s.rule(rest[:end])
+	if rule == nil {
+		return line, true
+	}
+
+	rule.seen.Add(1)
+	if rule.Rate < 1 && s.random() >= rule.Rate {
+		return "", false
+	}
+	rule.kept.Add(1)
+
+	if symbol != "c" || rule.Rate == 1 {
+		return line, true
+	}
+	i := strings.LastIndexByte(line, ':')
+	value, err := strconv.ParseFloat(line[i+1:], 64)
+	if err != nil {
+		return line, true
+	}
+	return fmt.Sprintf("%s:%f", line[:i], value/rule.Rate), true
+}
+
+func (s *SamplingWriter) Write(line string) {
+	if line, ok := s.sample(line); ok {
+		s.w.Write(line)
+	}
+}
+
+func (s *SamplingWriter) WriteBytes(line []byte) {
+	if sampled, ok := s.sample(string(line)); ok {
+		s.w.WriteString(sampled)
+	}
+}
+
+func (s *SamplingWriter) WriteString(line string) {
+	if line, ok := s.sample(line); ok {
+		s.w.WriteString(line)
+	}
+}
+
+// Stats returns the delivery statistics of the underlying writer, if it tracks them. The lines dropped by
+// sampling are not counted as drops.
+func (s *SamplingWriter) Stats() writer.Stats {
+	if sw, ok := s.w.(writer.StatsWriter); ok {
+		return sw.Stats()
+	}
+	return writer.Stats{}
+}
+
+// Flush flushes the underlying writer, if it buffers lines.
+func (s *SamplingWriter) Flush() error {
+	if f, ok := s.w.(writer.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close publishes the effective sample rates a last time, and closes the underlying writer.
+func (s *SamplingWriter) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+		s.publishStatus()
+		err = s.w.Close()
+	})
+	return err
+}
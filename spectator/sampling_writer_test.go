@@ -0,0 +1,168 @@
+package spectator
+
+import (
+	"fmt"
+	"github.com/Netflix/spectator-go/v2/spectator/meter"
+	"github.com/Netflix/spectator-go/v2/spectator/writer"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestSamplingWriter creates a SamplingWriter which keeps every other sampled update.
+func newTestSamplingWriter(t *testing.T, rules ...SampleRule) (*SamplingWriter, *writer.MemoryWriter) {
+	mw := &writer.MemoryWriter{}
+	s := newSamplingWriter(mw, rules, nil, time.Hour)
+	t.Cleanup(func() { _ = s.Close() })
+
+	var n int
+	s.random = func() float64 {
+		n++
+		if n%2 == 0 {
+			return 0.99
+		}
+		return 0
+	}
+	return s, mw
+}
+
+func TestSamplingWriter_Timers(t *testing.T) {
+	s, mw := newTestSamplingWriter(t, SampleRule{Pattern: "hot.*", Rate: 0.5})
+
+	timer := meter.NewTimer(meter.NewId("hot.timer", nil), s)
+	ds := meter.NewPercentileDistributionSummary(meter.NewId("hot.size", map[string]string{"a": "b"}), s)
+	for i := 0; i < 4; i++ {
+		timer.Record(time.Second)
+	}
+	for i := 0; i < 4; i++ {
+		ds.Record(10)
+	}
+
+	expected := []string{
+		"t:hot.timer:1.000000",
+		"t:hot.timer:1.000000",
+		"D:hot.size,a=b:10",
+		"D:hot.size,a=b:10",
+	}
+	if !reflect.DeepEqual(mw.Lines(), expected) {
+		t.Errorf("Expected half of the updates %v, got %v", expected, mw.Lines())
+	}
+}
+
+func TestSamplingWriter_ScalesCounters(t *testing.T) {
+	s, mw := newTestSamplingWriter(t, SampleRule{Pattern: "hot.counter", Rate: 0.5})
+
+	c := meter.NewCounter(meter.NewId("hot.counter", nil), s)
+	c.Add(3)
+	c.Add(3)
+
+	expected := []string{"c:hot.counter:6.000000"}
+	if !reflect.DeepEqual(mw.Lines(), expected) {
+		t.Errorf("Expected %v, got %v", expected, mw.Lines())
+	}
+}
+
+func TestSamplingWriter_Unsampled(t *testing.T) {
+	s, mw := newTestSamplingWriter(t, SampleRule{Pattern: "hot.*", Rate: 0})
+
+	meter.NewGauge(meter.NewId("hot.gauge", nil), s).Set(1)
+	meter.NewMonotonicCounter(meter.NewId("hot.monotonic", nil), s).Set(1)
+	meter.NewCounter(meter.NewId("cold.counter", nil), s).Increment()
+	meter.NewCounter(meter.NewId("hot.counter", nil), s).Increment()
+
+	expected := []string{
+		"g:hot.gauge:1.000000",
+		"C:hot.monotonic:1.000000",
+		"c:cold.counter:1",
+	}
+	if !reflect.DeepEqual(mw.Lines(), expected) {
+		t.Errorf("Expected %v, got %v", expected, mw.Lines())
+	}
+}
+
+func TestSamplingWriter_FirstRuleApplies(t *testing.T) {
+	s, mw := newTestSamplingWriter(t,
+		SampleRule{Pattern: "hot.counter", Rate: 1},
+		SampleRule{Pattern: "hot.*", Rate: 0},
+	)
+
+	meter.NewCounter(meter.NewId("hot.counter", nil), s).Increment()
+	meter.NewCounter(meter.NewId("hot.other", nil), s).Increment()
+
+	expected := []string{"c:hot.counter:1"}
+	if !reflect.DeepEqual(mw.Lines(), expected) {
+		t.Errorf("Expected %v, got %v", expected, mw.Lines())
+	}
+}
+
+func TestSamplingWriter_NamesCacheIsBounded(t *testing.T) {
+	s, mw := newTestSamplingWriter(t, SampleRule{Pattern: "hot.*", Rate: 0})
+
+	for i := 0; i < maxSampleNames+10; i++ {
+		s.Write(fmt.Sprintf("c:hot.%d:1", i))
+	}
+	s.Write("c:cold:1")
+
+	if n := s.namesCount.Load(); n != maxSampleNames {
+		t.Errorf("Expected %d cached names, got %d", maxSampleNames, n)
+	}
+	expected := []string{"c:cold:1"}
+	if !reflect.DeepEqual(mw.Lines(), expected) {
+		t.Errorf("Expected the rules to apply beyond the cache, got %v", mw.Lines())
+	}
+}
+
+func TestSamplingWriter_Status(t *testing.T) {
+	s, mw := newTestSamplingWriter(t,
+		SampleRule{Pattern: "hot.*", Rate: 0.5},
+		SampleRule{Pattern: "idle", Rate: 2},
+	)
+
+	timer := meter.NewTimer(meter.NewId("hot.timer", nil), s)
+	for i := 0; i < 4; i++ {
+		timer.Record(time.Second)
+	}
+	mw.Reset()
+
+	s.publishStatus()
+	expected := []string{
+		"g:spectator-go.samplingWriter.sampleRate,pattern=hot._:0.500000",
+		"g:spectator-go.samplingWriter.sampleRate,pattern=idle:1.000000",
+	}
+	if !reflect.DeepEqual(mw.Lines(), expected) {
+		t.Errorf("Expected %v, got %v", expected, mw.Lines())
+	}
+}
+
+func TestRegistry_Sampling(t *testing.T) {
+	config, err := NewConfigWithOptions(
+		WithLocation("memory"),
+		WithCommonTags(map[string]string{"nf.app": "api"}),
+		WithSampling(SampleRule{Pattern: "hot.*", Rate: 0}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRegistry(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, ok := r.GetWriter().(*SamplingWriter)
+	if !ok {
+		t.Fatalf("Expected a SamplingWriter, got %T", r.GetWriter())
+	}
+	mw := s.w.(*writer.MemoryWriter)
+
+	r.Counter("hot.counter", nil).Increment()
+	r.Counter("cold.counter", nil).Increment()
+	r.Close()
+
+	expected := []string{
+		"c:cold.counter,nf.app=api:1",
+		"g:spectator-go.samplingWriter.sampleRate,nf.app=api,pattern=hot._:0.000000",
+	}
+	if !reflect.DeepEqual(mw.Lines(), expected) {
+		t.Errorf("Expected %v, got %v", expected, mw.Lines())
+	}
+}
@@ -0,0 +1,203 @@
+package spectator
+
+import (
+	"github.com/Netflix/spectator-go/v2/spectator/writer"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// swapWriter is the writer held by the meters of a Registry. It forwards to the current writer, so that
+// Reconfigure can replace the writer of the meters that already exist.
+//
+// The current writer is held in an atomic pointer, so that the writes do not take a lock. Each write is
+// counted as in flight on the writer it loaded, and swap and Close wait for the writes in flight on the
+// previous writer to complete, so that no write reaches a writer after it is closed. After Close, the
+// writes are discarded, and counted.
+//
+// The lines are also copied to the tees, such as a DebugHandler, which are not closed with the writer.
+type swapWriter struct {
+	target    atomic.Pointer[swapTarget]
+	discarded atomic.Uint64
+	tees      atomic.Pointer[[]writer.Writer]
+
+	// mu serializes swap, Close and the changes to the tees. It is never held by the writes.
+	mu sync.Mutex
+}
+
+// swapTarget is the writer that the writes are forwarded to, or the closed state.
+type swapTarget struct {
+	w        writer.Writer
+	closed   bool
+	inflight atomic.Int64
+}
+
+// Used to validate that swapWriter forwards the optional interfaces at build time.
+var (
+	_ writer.StatsWriter = (*swapWriter)(nil)
+	_ writer.Flusher     = (*swapWriter)(nil)
+)
+
+func newSwapWriter(w writer.Writer) *swapWriter {
+	s := &swapWriter{}
+	s.target.Store(&swapTarget{w: w})
+	return s
+}
+
+// acquire returns the current target, with the write counted as in flight, or nil once closed. The
+// caller must call release on the target after the write.
+func (s *swapWriter) acquire() *swapTarget {
+	for {
+		t := s.target.Load()
+		if t.closed {
+			s.discarded.Add(1)
+			return nil
+		}
+		t.inflight.Add(1)
+		if s.target.Load() == t {
+			return t
+		}
+		// swapped before the write was counted, so the previous writer may already be drained
+		t.inflight.Add(-1)
+	}
+}
+
+func (t *swapTarget) release() {
+	t.inflight.Add(-1)
+}
+
+// drain waits for the writes in flight on the target to complete. The target must no longer be current.
+func (t *swapTarget) drain() {
+	for t.inflight.Load() > 0 {
+		runtime.Gosched()
+	}
+}
+
+func (s *swapWriter) Write(line string) {
+	t := s.acquire()
+	if t == nil {
+		return
+	}
+	defer t.release()
+	t.w.Write(line)
+	if tees := s.tees.Load(); tees != nil {
+		for _, tee := range *tees {
+			tee.Write(line)
+		}
+	}
+}
+
+func (s *swapWriter) WriteBytes(line []byte) {
+	t := s.acquire()
+	if t == nil {
+		return
+	}
+	defer t.release()
+	t.w.WriteBytes(line)
+	if tees := s.tees.Load(); tees != nil {
+		for _, tee := range *tees {
+			tee.WriteBytes(line)
+		}
+	}
+}
+
+func (s *swapWriter) WriteString(line string) {
+	t := s.acquire()
+	if t == nil {
+		return
+	}
+	defer t.release()
+	t.w.WriteString(line)
+	if tees := s.tees.Load(); tees != nil {
+		for _, tee := range *tees {
+			tee.WriteString(line)
+		}
+	}
+}
+
+// Stats returns the delivery statistics of the current writer, if it tracks them.
+func (s *swapWriter) Stats() writer.Stats {
+	if sw, ok := s.current().(writer.StatsWriter); ok {
+		return sw.Stats()
+	}
+	return writer.Stats{}
+}
+
+// Flush flushes the current writer, if it buffers lines.
+func (s *swapWriter) Flush() error {
+	if f, ok := s.current().(writer.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close closes the current writer. The writes made afterwards are discarded. It is safe to call more
+// than once.
+func (s *swapWriter) Close() error {
+	s.mu.Lock()
+	t := s.target.Load()
+	if t.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.target.Store(&swapTarget{w: t.w, closed: true})
+	s.mu.Unlock()
+
+	// the writer may take a while to flush, so close it without blocking the writes, which are discarded
+	t.drain()
+	return t.w.Close()
+}
+
+// current returns the writer that the writes are forwarded to, or the last one, once closed.
+func (s *swapWriter) current() writer.Writer {
+	return s.target.Load().w
+}
+
+// swap replaces the writer, waits for the writes in flight on the previous one, and returns it, so that it
+// can be closed. It reports false, without replacing the writer, if the swapWriter is closed.
+func (s *swapWriter) swap(w writer.Writer) (writer.Writer, bool) {
+	s.mu.Lock()
+	t := s.target.Load()
+	if t.closed {
+		s.mu.Unlock()
+		return nil, false
+	}
+	s.target.Store(&swapTarget{w: w})
+	s.mu.Unlock()
+
+	t.drain()
+	return t.w, true
+}
+
+// addTee copies the lines written from now on to the tee.
+func (s *swapWriter) addTee(tee writer.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var tees []writer.Writer
+	if current := s.tees.Load(); current != nil {
+		tees = append(tees, *current...)
+	}
+	tees = append(tees, tee)
+	s.tees.Store(&tees)
+}
+
+// removeTee stops copying the lines to the tee.
+func (s *swapWriter) removeTee(tee writer.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := s.tees.Load()
+	if current == nil {
+		return
+	}
+	var tees []writer.Writer
+	for _, t := range *current {
+		if t != tee {
+			tees = append(tees, t)
+		}
+	}
+	if len(tees) == 0 {
+		s.tees.Store(nil)
+		return
+	}
+	s.tees.Store(&tees)
+}
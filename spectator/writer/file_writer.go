@@ -1,26 +1,175 @@
 package writer
 
 import (
+	"bufio"
+	"compress/gzip"
 	"fmt"
 	"github.com/Netflix/spectator-go/v2/spectator/logger"
+	"io"
+	"log/slog"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
+// rotatedFileTimeFormat is used to name rotated files, so that a lexical sort is also chronological.
+const rotatedFileTimeFormat = "20060102T150405.000000000"
+
+// FileOptions configures rotation, retention, compression and buffering for the FileWriter. The zero
+// value appends to a single file, without buffering, which is the original behavior.
+type FileOptions struct {
+	// MaxSize rotates the file before a write would grow it beyond this many bytes. Zero disables
+	// size-based rotation.
+	MaxSize int64
+	// RotateInterval rotates the file on the first write after it has been open for this long. Zero
+	// disables time-based rotation.
+	RotateInterval time.Duration
+	// MaxFiles is the number of rotated files to retain, in addition to the active file. The oldest
+	// files are removed first. Zero retains all rotated files.
+	MaxFiles int
+	// Compress gzips rotated files in the background.
+	Compress bool
+	// BufferSize enables buffered writes with a buffer of this many bytes. Zero disables buffering.
+	BufferSize int
+	// FlushInterval is the maximum time that buffered lines wait before they are written to the file.
+	// Defaults to 5 seconds, when buffering is enabled.
+	FlushInterval time.Duration
+}
+
 type FileWriter struct {
-	file   *os.File
-	logger logger.Logger
+	path    string
+	options FileOptions
+	logger  logger.Logger
+	stats   writerStats
+
+	// mu guards the file, the buffer and the rotation state, which are shared by concurrent writers.
+	mu         sync.Mutex
+	file       *os.File
+	buffer     *bufio.Writer
+	size       int64
+	openedAt   time.Time
+	flushTimer *time.Timer
+	closed     bool
+
+	// Compression and retention run in the background, one rotation at a time.
+	rotationMu sync.Mutex
+	rotationWg sync.WaitGroup
 }
 
 func NewFileWriter(filename string, logger logger.Logger) (*FileWriter, error) {
-	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
+	return NewFileWriterWithOptions(filename, FileOptions{}, logger)
+}
+
+// NewFileWriterWithOptions creates a FileWriter which rotates, compresses and buffers according to the
+// provided options.
+func NewFileWriterWithOptions(filename string, options FileOptions, logger logger.Logger) (*FileWriter, error) {
+	if options.BufferSize > 0 && options.FlushInterval <= 0 {
+		options.FlushInterval = 5 * time.Second
+	}
+
+	f := &FileWriter{
+		path:    filename,
+		options: options,
+		logger:  logger,
+	}
+
+	if err := f.openLocked(); err != nil {
 		return nil, err
 	}
-	return &FileWriter{file, logger}, nil
+
+	if options.BufferSize > 0 {
+		f.startFlushTimer()
+	}
+
+	return f, nil
+}
+
+// ParseFileLocation splits a `file://` output location into the file path and the FileOptions encoded
+// in the query string. The supported parameters are:
+//
+//   - `maxSize`        - Rotate by size, in bytes, with an optional KB, MB or GB suffix (e.g. `100MB`).
+//   - `rotateInterval` - Rotate by time, as a Go duration (e.g. `1h`).
+//   - `maxFiles`       - Number of rotated files to retain.
+//   - `compress`       - Gzip rotated files (`true` or `false`).
+//   - `bufferSize`     - Buffer writes, in bytes, with an optional KB, MB or GB suffix.
+//   - `flushInterval`  - Maximum delay for buffered writes, as a Go duration.
+//
+// For example: `file:///var/log/metrics.log?maxSize=100MB&maxFiles=5&compress=true`.
+func ParseFileLocation(location string) (string, FileOptions, error) {
+	var options FileOptions
+
+	location = strings.TrimPrefix(location, "file://")
+	path, rawQuery, _ := strings.Cut(location, "?")
+	if path == "" {
+		return "", options, fmt.Errorf("file location is missing a path")
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", options, fmt.Errorf("invalid file location options: %w", err)
+	}
+
+	for key, values := range query {
+		value := values[len(values)-1]
+		switch key {
+		case "maxSize":
+			options.MaxSize, err = parseByteSize(value)
+		case "rotateInterval":
+			options.RotateInterval, err = time.ParseDuration(value)
+		case "maxFiles":
+			options.MaxFiles, err = strconv.Atoi(value)
+		case "compress":
+			options.Compress, err = strconv.ParseBool(value)
+		case "bufferSize":
+			var size int64
+			size, err = parseByteSize(value)
+			options.BufferSize = int(size)
+		case "flushInterval":
+			options.FlushInterval, err = time.ParseDuration(value)
+		default:
+			err = fmt.Errorf("unknown option")
+		}
+		if err != nil {
+			return "", options, fmt.Errorf("invalid file location option %s=%s: %w", key, value, err)
+		}
+	}
+
+	if options.MaxSize < 0 || options.RotateInterval < 0 || options.MaxFiles < 0 || options.BufferSize < 0 || options.FlushInterval < 0 {
+		return "", options, fmt.Errorf("file location options may not be negative")
+	}
+
+	return path, options, nil
+}
+
+func parseByteSize(value string) (int64, error) {
+	multiplier := int64(1)
+	upper := strings.ToUpper(value)
+	for suffix, m := range map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30} {
+		if strings.HasSuffix(upper, suffix) {
+			multiplier = m
+			value = value[:len(value)-len(suffix)]
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return n * multiplier, nil
 }
 
 func (f *FileWriter) Write(line string) {
-	f.logger.Debugf("Sending line: %s", line)
+	if logger.Enabled(f.logger, logger.LevelDebug) {
+		logger.LogAttrs(f.logger, logger.LevelDebug, "Sending line",
+			slog.String("location", "file://"+f.path), slog.String("line", line))
+	}
+	f.stats.recordLine()
 	f.WriteString(line)
 }
 
@@ -29,12 +178,271 @@ func (f *FileWriter) WriteBytes(line []byte) {
 }
 
 func (f *FileWriter) WriteString(line string) {
-	_, err := fmt.Fprintln(f.file, line)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		f.stats.drops.Add(1)
+		return
+	}
+
+	if f.shouldRotateLocked(int64(len(line) + 1)) {
+		if err := f.rotateLocked(); err != nil {
+			f.stats.recordError()
+			f.logger.Errorf("Error rotating file: %s", err)
+			return
+		}
+	}
+
+	var out io.Writer = f.file
+	if f.buffer != nil {
+		out = f.buffer
+	}
+
+	n, err := fmt.Fprintln(out, line)
+	f.size += int64(n)
+	if err != nil {
+		f.stats.recordError()
+		logger.LogAttrs(f.logger, logger.LevelError, "Error writing to file",
+			slog.String("location", "file://"+f.path), slog.Any("error", err))
+		return
+	}
+	f.stats.recordBytes(n)
+}
+
+// Stats returns the delivery statistics of the writer.
+func (f *FileWriter) Stats() Stats {
+	return f.stats.snapshot()
+}
+
+// openLocked opens the file for appending, and resets the rotation state. The caller must hold f.mu.
+func (f *FileWriter) openLocked() error {
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	if f.options.BufferSize > 0 {
+		f.buffer = bufio.NewWriterSize(file, f.options.BufferSize)
+	}
+	return nil
+}
+
+// shouldRotateLocked reports whether the file must be rotated before writing n bytes. An empty file
+// is never rotated. The caller must hold f.mu.
+func (f *FileWriter) shouldRotateLocked(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.options.MaxSize > 0 && f.size+n > f.options.MaxSize {
+		return true
+	}
+	if f.options.RotateInterval > 0 && time.Since(f.openedAt) >= f.options.RotateInterval {
+		return true
+	}
+	return false
+}
+
+// rotateLocked flushes and closes the active file, renames it with a timestamp suffix, and opens a new
+// active file. Compression and retention of rotated files run in the background. The caller must hold
+// f.mu.
+func (f *FileWriter) rotateLocked() error {
+	if err := f.syncAndCloseLocked(); err != nil {
+		f.logger.Errorf("Error closing file for rotation: %s", err)
+	}
+
+	rotated := f.path + "." + time.Now().Format(rotatedFileTimeFormat)
+	if err := os.Rename(f.path, rotated); err != nil {
+		// reopen the original file, so that writes can continue
+		if openErr := f.openLocked(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+
+	if err := f.openLocked(); err != nil {
+		return err
+	}
+
+	f.rotationWg.Add(1)
+	go f.finishRotation(rotated)
+	return nil
+}
+
+// finishRotation compresses the rotated file, if enabled, and removes the oldest rotated files that
+// exceed the retention limit.
+func (f *FileWriter) finishRotation(rotated string) {
+	defer f.rotationWg.Done()
+
+	f.rotationMu.Lock()
+	defer f.rotationMu.Unlock()
+
+	if f.options.Compress {
+		if err := gzipFile(rotated); err != nil {
+			f.logger.Errorf("Error compressing rotated file %s: %s", rotated, err)
+		}
+	}
+
+	if f.options.MaxFiles > 0 {
+		f.removeOldFiles()
+	}
+}
+
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
 	if err != nil {
-		f.logger.Errorf("Error writing to file: %s", err)
+		return err
 	}
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// isRotatedFile reports whether the file name is the prefix followed by a rotation timestamp, and an
+// optional `.gz` suffix, so that the other files next to the log, such as `metrics.log.bak`, are kept.
+func isRotatedFile(name string, prefix string) bool {
+	suffix, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return false
+	}
+	suffix = strings.TrimSuffix(suffix, ".gz")
+	_, err := time.Parse(rotatedFileTimeFormat, suffix)
+	return err == nil
 }
 
+// rotatedFiles returns the rotated files that belong to this writer, oldest first.
+func (f *FileWriter) rotatedFiles() ([]string, error) {
+	dir := filepath.Dir(f.path)
+	prefix := filepath.Base(f.path) + "."
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && isRotatedFile(entry.Name(), prefix) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (f *FileWriter) removeOldFiles() {
+	files, err := f.rotatedFiles()
+	if err != nil {
+		f.logger.Errorf("Error listing rotated files: %s", err)
+		return
+	}
+
+	for len(files) > f.options.MaxFiles {
+		if err := os.Remove(files[0]); err != nil {
+			f.logger.Errorf("Error removing rotated file %s: %s", files[0], err)
+		}
+		files = files[1:]
+	}
+}
+
+func (f *FileWriter) startFlushTimer() {
+	f.flushTimer = time.AfterFunc(f.options.FlushInterval, f.flushPeriodically)
+}
+
+func (f *FileWriter) flushPeriodically() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return
+	}
+
+	if err := f.buffer.Flush(); err != nil {
+		f.stats.recordError()
+		f.logger.Errorf("Error flushing file buffer: %s", err)
+	}
+	f.stats.flushes.Add(1)
+
+	f.startFlushTimer()
+}
+
+// Flush writes the buffered lines to the file. It does not fsync the file, which is done by Close.
+func (f *FileWriter) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed || f.buffer == nil {
+		return nil
+	}
+
+	f.stats.flushes.Add(1)
+	if err := f.buffer.Flush(); err != nil {
+		f.stats.recordError()
+		return err
+	}
+	return nil
+}
+
+// syncAndCloseLocked flushes the buffer, fsyncs the file, and closes it. The caller must hold f.mu.
+func (f *FileWriter) syncAndCloseLocked() error {
+	var err error
+	if f.buffer != nil {
+		err = f.buffer.Flush()
+		f.stats.flushes.Add(1)
+	}
+	if syncErr := f.file.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Close flushes any buffered lines, and guarantees that they are synced to disk before the file is
+// closed. It waits for the compression and retention of rotated files to finish.
 func (f *FileWriter) Close() error {
-	return f.file.Close()
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+	if f.flushTimer != nil {
+		f.flushTimer.Stop()
+	}
+	err := f.syncAndCloseLocked()
+	f.mu.Unlock()
+
+	f.rotationWg.Wait()
+	return err
 }
@@ -1,10 +1,15 @@
 package writer
 
 import (
+	"compress/gzip"
+	"fmt"
 	"github.com/Netflix/spectator-go/v2/spectator/logger"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 const testFileName = "test.txt"
@@ -93,3 +98,160 @@ func TestFileWriter_Close(t *testing.T) {
 		t.Errorf("Unexpected error: %v", err)
 	}
 }
+
+func TestParseFileLocation(t *testing.T) {
+	path, options, err := ParseFileLocation("file:///tmp/metrics.log?maxSize=1MB&rotateInterval=1h&maxFiles=3&compress=true&bufferSize=64KB&flushInterval=2s")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if path != "/tmp/metrics.log" {
+		t.Errorf("Expected '/tmp/metrics.log', got '%s'", path)
+	}
+
+	expected := FileOptions{
+		MaxSize:        1 << 20,
+		RotateInterval: time.Hour,
+		MaxFiles:       3,
+		Compress:       true,
+		BufferSize:     64 << 10,
+		FlushInterval:  2 * time.Second,
+	}
+	if options != expected {
+		t.Errorf("Expected %+v, got %+v", expected, options)
+	}
+}
+
+func TestParseFileLocation_Invalid(t *testing.T) {
+	locations := []string{
+		"file://",
+		"file:///tmp/metrics.log?maxSize=big",
+		"file:///tmp/metrics.log?maxFiles=-1",
+		"file:///tmp/metrics.log?unknown=1",
+	}
+
+	for _, location := range locations {
+		if _, _, err := ParseFileLocation(location); err == nil {
+			t.Errorf("Expected error for location '%s'", location)
+		}
+	}
+}
+
+func TestFileWriter_RotateBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.log")
+
+	writer, err := NewFileWriterWithOptions(path, FileOptions{MaxSize: 20, MaxFiles: 2}, logger.NewDefaultLogger())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Each line is 10 bytes with the newline, so every other line triggers a rotation
+	for i := 0; i < 8; i++ {
+		writer.Write(fmt.Sprintf("c:line:%02d", i))
+	}
+	_ = writer.Close()
+
+	rotated, _ := writer.rotatedFiles()
+	if len(rotated) != 2 {
+		t.Fatalf("Expected 2 rotated files, got %d: %v", len(rotated), rotated)
+	}
+
+	content, _ := os.ReadFile(rotated[1])
+	if string(content) != "c:line:04\nc:line:05\n" {
+		t.Errorf("Unexpected rotated file content '%s'", string(content))
+	}
+
+	content, _ = os.ReadFile(path)
+	if string(content) != "c:line:06\nc:line:07\n" {
+		t.Errorf("Unexpected active file content '%s'", string(content))
+	}
+}
+
+func TestFileWriter_RetentionKeepsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metrics.log")
+	for _, name := range []string{"metrics.log.bak", "metrics.log.lock", "metrics.log.20240101T000000.gz.tmp"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writer, err := NewFileWriterWithOptions(path, FileOptions{MaxSize: 20, MaxFiles: 1}, logger.NewDefaultLogger())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i := 0; i < 8; i++ {
+		writer.Write(fmt.Sprintf("c:line:%02d", i))
+	}
+	_ = writer.Close()
+
+	rotated, _ := writer.rotatedFiles()
+	if len(rotated) != 1 {
+		t.Errorf("Expected 1 rotated file, got %d: %v", len(rotated), rotated)
+	}
+	for _, name := range []string{"metrics.log.bak", "metrics.log.lock", "metrics.log.20240101T000000.gz.tmp"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s to be kept, got %v", name, err)
+		}
+	}
+}
+
+func TestFileWriter_RotateByInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.log")
+
+	writer, _ := NewFileWriterWithOptions(path, FileOptions{RotateInterval: 10 * time.Millisecond}, logger.NewDefaultLogger())
+	writer.Write("line1")
+	time.Sleep(20 * time.Millisecond)
+	writer.Write("line2")
+	_ = writer.Close()
+
+	rotated, _ := writer.rotatedFiles()
+	if len(rotated) != 1 {
+		t.Fatalf("Expected 1 rotated file, got %d: %v", len(rotated), rotated)
+	}
+}
+
+func TestFileWriter_RotateWithCompression(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.log")
+
+	writer, _ := NewFileWriterWithOptions(path, FileOptions{MaxSize: 10, Compress: true}, logger.NewDefaultLogger())
+	writer.Write("line1")
+	writer.Write("line2")
+	_ = writer.Close()
+
+	rotated, _ := writer.rotatedFiles()
+	if len(rotated) != 1 || !strings.HasSuffix(rotated[0], ".gz") {
+		t.Fatalf("Expected 1 compressed rotated file, got %v", rotated)
+	}
+
+	file, _ := os.Open(rotated[0])
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, _ := io.ReadAll(zr)
+	if string(content) != "line1\n" {
+		t.Errorf("Expected 'line1\\n', got '%s'", string(content))
+	}
+}
+
+func TestFileWriter_BufferedFlushOnClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.log")
+
+	writer, _ := NewFileWriterWithOptions(path, FileOptions{BufferSize: 4096, FlushInterval: time.Minute}, logger.NewDefaultLogger())
+	writer.Write("test line")
+
+	content, _ := os.ReadFile(path)
+	if len(content) != 0 {
+		t.Errorf("Expected buffered line not to be written before close, got '%s'", string(content))
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	content, _ = os.ReadFile(path)
+	if string(content) != "test line\n" {
+		t.Errorf("Expected 'test line\\n', got '%s'", string(content))
+	}
+}
@@ -2,6 +2,7 @@ package writer
 
 import (
 	"github.com/Netflix/spectator-go/v2/spectator/logger"
+	"log/slog"
 	"strconv"
 	"strings"
 	"sync"
@@ -18,6 +19,7 @@ type LineBuffer struct {
 	flushInterval time.Duration
 	lastFlush     time.Time
 	flushTimer    *time.Timer
+	stats         writerStats
 
 	mu sync.Mutex
 }
@@ -52,6 +54,7 @@ func (lb *LineBuffer) Write(line string) {
 	lb.lineCount++
 
 	if lb.buffer.Len() >= lb.bufferSize {
+		lb.stats.overflows.Add(1)
 		lb.writer.WriteString("c:spectator-go.lineBuffer.overflows:1")
 		lb.flush()
 	}
@@ -78,12 +81,22 @@ func (lb *LineBuffer) flush() {
 		return
 	}
 
-	lb.logger.Debugf("Flushing buffer with %d lines (%d bytes)", lb.lineCount, lb.buffer.Len())
+	logger.LogAttrs(lb.logger, logger.LevelDebug, "Flushing buffer",
+		slog.Int("lines", lb.lineCount), slog.Int("bytes", lb.buffer.Len()))
 	lb.writer.WriteString(lb.buffer.String())
 	lb.writer.WriteString("c:spectator-go.lineBuffer.bytesWritten:" + strconv.Itoa(lb.buffer.Len()))
 	lb.buffer.Reset()
 	lb.lineCount = 0
 	lb.lastFlush = time.Now()
+	lb.stats.flushes.Add(1)
+}
+
+// Flush delivers the buffered lines immediately.
+func (lb *LineBuffer) Flush() {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	lb.flush()
 }
 
 func (lb *LineBuffer) Close() {
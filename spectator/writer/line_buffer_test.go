@@ -93,3 +93,17 @@ func TestLineBuffer_FlushesOnClose(t *testing.T) {
 		}
 	}
 }
+
+func TestLineBuffer_Flush(t *testing.T) {
+	memWriter := &MemoryWriter{}
+	buffer := NewLineBuffer(memWriter, logger.NewDefaultLogger(), 1000, 5*time.Second)
+	defer buffer.Close()
+
+	buffer.Write("line1")
+	buffer.Flush()
+
+	lines := memWriter.Lines()
+	if len(lines) != 2 || lines[0] != "line1" {
+		t.Errorf("Expected the line and the bytesWritten metric after flush, got %v", lines)
+	}
+}
@@ -3,6 +3,7 @@ package writer
 import (
 	"fmt"
 	"github.com/Netflix/spectator-go/v2/spectator/logger"
+	"log/slog"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -84,8 +85,13 @@ type LowLatencyBuffer struct {
 	// Distribute writes across the shards in the active buffer, with a round-robin scheme.
 	counter uint64
 
-	stopCh chan struct{}
-	wg     sync.WaitGroup
+	stats writerStats
+
+	// flushMu serializes the periodic flushes with the flushes requested through Flush.
+	flushMu   sync.Mutex
+	stopCh    chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
 }
 
 func NewLowLatencyBuffer(writer Writer, logger logger.Logger, bufferSize int, flushInterval time.Duration) *LowLatencyBuffer {
@@ -158,6 +164,8 @@ func (llb *LowLatencyBuffer) Write(line string) {
 	idx := buffer.getChunkIndexForLine(lineBytes)
 	if idx == -1 {
 		// overflows (drops) are counted in getChunkIndexForLine, for metric reporting
+		llb.stats.overflows.Add(1)
+		llb.stats.drops.Add(1)
 		return
 	}
 
@@ -188,8 +196,17 @@ func (llb *LowLatencyBuffer) flushLoop() {
 	}
 }
 
+// Flush delivers the lines in both buffer sets, by swapping and flushing them twice.
+func (llb *LowLatencyBuffer) Flush() {
+	llb.swapAndFlush()
+	llb.swapAndFlush()
+}
+
 // swapAndFlush swaps the front and back buffers and flushes the deactivated buffers
 func (llb *LowLatencyBuffer) swapAndFlush() {
+	llb.flushMu.Lock()
+	defer llb.flushMu.Unlock()
+
 	// Swap the buffer sets, so one can be drained, while the other accepts application writes
 	old := llb.useFrontBuffers.Load()
 	llb.useFrontBuffers.CompareAndSwap(old, !old)
@@ -211,6 +228,11 @@ func (llb *LowLatencyBuffer) swapAndFlush() {
 	for _, buffer := range buffersToFlush {
 		bytesWritten += llb.flushBufferShard(buffer, bufferSet)
 	}
+	llb.stats.flushes.Add(1)
+	if bytesWritten > 0 {
+		logger.LogAttrs(llb.logger, logger.LevelDebug, "Flushing buffer",
+			slog.String("bufferSet", bufferSet), slog.Int("bytes", bytesWritten))
+	}
 
 	pctUsage := float64(bytesWritten) / float64(llb.bufferSetSize)
 	if bytesWritten > 0 {
@@ -250,9 +272,12 @@ func (llb *LowLatencyBuffer) flushBufferShard(buffer *bufferShard, bufferSet str
 	return bytesWritten
 }
 
+// Close stops the flush goroutine, after a final flush. It is safe to call more than once.
 func (llb *LowLatencyBuffer) Close() {
 	// Signal the flush goroutine to stop
-	close(llb.stopCh)
+	llb.closeOnce.Do(func() {
+		close(llb.stopCh)
+	})
 
 	// Wait for the goroutine to finish
 	llb.wg.Wait()
@@ -190,3 +190,31 @@ func TestLowLatencyBuffer_ChunkBoundaries_OverMaxSizeIsDropped(t *testing.T) {
 		t.Errorf("Expected %d lines, got %d", 0, len(lines))
 	}
 }
+
+func TestLowLatencyBuffer_Flush(t *testing.T) {
+	memWriter := &MemoryWriter{}
+	buffer := NewLowLatencyBuffer(memWriter, logger.NewDefaultLogger(), 2*chunkSize*runtime.NumCPU(), time.Hour)
+	defer buffer.Close()
+
+	buffer.Write("line1")
+	buffer.Flush()
+
+	lines := splitAndFilterMetricLines(memWriter)
+	if len(lines) != 1 || lines[0] != "line1" {
+		t.Errorf("Expected [line1] after flush, got %v", lines)
+	}
+}
+
+func TestLowLatencyBuffer_CloseTwice(t *testing.T) {
+	memWriter := &MemoryWriter{}
+	buffer := NewLowLatencyBuffer(memWriter, logger.NewDefaultLogger(), 2*chunkSize*runtime.NumCPU(), time.Hour)
+
+	buffer.Write("line1")
+	buffer.Close()
+	buffer.Close()
+
+	lines := splitAndFilterMetricLines(memWriter)
+	if len(lines) != 1 || lines[0] != "line1" {
+		t.Errorf("Expected [line1] after close, got %v", lines)
+	}
+}
@@ -9,9 +9,11 @@ import (
 type MemoryWriter struct {
 	lines []string
 	mu    sync.RWMutex
+	stats writerStats
 }
 
 func (m *MemoryWriter) Write(line string) {
+	m.stats.recordLine()
 	m.WriteString(line)
 }
 
@@ -23,6 +25,7 @@ func (m *MemoryWriter) WriteString(line string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.lines = append(m.lines, line)
+	m.stats.recordBytes(len(line))
 }
 
 func (m *MemoryWriter) Lines() []string {
@@ -37,6 +40,11 @@ func (m *MemoryWriter) Reset() {
 	m.lines = []string{}
 }
 
+// Stats returns the delivery statistics of the writer.
+func (m *MemoryWriter) Stats() Stats {
+	return m.stats.snapshot()
+}
+
 func (m *MemoryWriter) Close() error {
 	return nil
 }
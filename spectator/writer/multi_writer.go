@@ -0,0 +1,119 @@
+package writer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// multiLocationPrefix starts a composite location, such as `multi:udp,file:///tmp/metrics.log`.
+const multiLocationPrefix = "multi:"
+
+// MultiWriter writes each line to several writers, such as spectatord and a local file during a migration.
+// The writers are isolated from each other: each one handles its own delivery errors, and a failure to
+// flush or close one of them does not prevent the others from being flushed or closed.
+type MultiWriter struct {
+	writers []Writer
+	stats   writerStats
+}
+
+// NewMultiWriter creates a *MultiWriter, which writes to each of the writers, in order.
+func NewMultiWriter(writers ...Writer) *MultiWriter {
+	return &MultiWriter{writers: append([]Writer(nil), writers...)}
+}
+
+// ParseMultiLocation returns the locations of a composite location, such as
+// `multi:udp,file:///tmp/metrics.log`. Each location must be a valid output location, other than a
+// composite location.
+func ParseMultiLocation(location string) ([]string, error) {
+	if !strings.HasPrefix(location, multiLocationPrefix) {
+		return nil, fmt.Errorf("invalid multi location %s: missing %s prefix", location, multiLocationPrefix)
+	}
+
+	locations := strings.Split(strings.TrimPrefix(location, multiLocationPrefix), ",")
+	for _, l := range locations {
+		if l == "" {
+			return nil, fmt.Errorf("invalid multi location %s: empty location", location)
+		}
+		if strings.HasPrefix(l, multiLocationPrefix) || !IsValidOutputLocation(l) {
+			return nil, fmt.Errorf("invalid multi location %s: invalid location %s", location, l)
+		}
+	}
+	return locations, nil
+}
+
+func isValidMultiLocation(output string) bool {
+	_, err := ParseMultiLocation(output)
+	return err == nil
+}
+
+func (m *MultiWriter) Write(line string) {
+	m.stats.recordLine()
+	for _, w := range m.writers {
+		w.Write(line)
+	}
+}
+
+func (m *MultiWriter) WriteBytes(line []byte) {
+	for _, w := range m.writers {
+		w.WriteBytes(line)
+	}
+}
+
+func (m *MultiWriter) WriteString(line string) {
+	for _, w := range m.writers {
+		w.WriteString(line)
+	}
+}
+
+// Writers returns the writers, in order.
+func (m *MultiWriter) Writers() []Writer {
+	return append([]Writer(nil), m.writers...)
+}
+
+// Stats returns the number of lines written to the MultiWriter, and the sum of the other statistics of
+// the writers which track them. The most recent LastErrorTime of the writers is reported.
+func (m *MultiWriter) Stats() Stats {
+	stats := Stats{LinesWritten: m.stats.snapshot().LinesWritten}
+	for _, w := range m.writers {
+		sw, ok := w.(StatsWriter)
+		if !ok {
+			continue
+		}
+		s := sw.Stats()
+		stats.BytesWritten += s.BytesWritten
+		stats.WriteErrors += s.WriteErrors
+		stats.Drops += s.Drops
+		stats.Overflows += s.Overflows
+		stats.Flushes += s.Flushes
+		stats.Reconnects += s.Reconnects
+		if s.LastErrorTime.After(stats.LastErrorTime) {
+			stats.LastErrorTime = s.LastErrorTime
+		}
+	}
+	return stats
+}
+
+// Flush flushes each of the writers which buffer lines, and returns their errors, joined.
+func (m *MultiWriter) Flush() error {
+	var errs []error
+	for i, w := range m.writers {
+		if f, ok := w.(Flusher); ok {
+			if err := f.Flush(); err != nil {
+				errs = append(errs, fmt.Errorf("writer %d: %w", i, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes each of the writers, and returns their errors, joined.
+func (m *MultiWriter) Close() error {
+	var errs []error
+	for i, w := range m.writers {
+		if err := w.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("writer %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
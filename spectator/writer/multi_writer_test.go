@@ -0,0 +1,128 @@
+package writer
+
+import (
+	"errors"
+	"github.com/Netflix/spectator-go/v2/spectator/logger"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// failingWriter is a writer whose Flush and Close fail.
+type failingWriter struct {
+	MemoryWriter
+	closed bool
+}
+
+func (f *failingWriter) Flush() error {
+	return errors.New("flush failed")
+}
+
+func (f *failingWriter) Close() error {
+	f.closed = true
+	return errors.New("close failed")
+}
+
+func TestMultiWriter_Write(t *testing.T) {
+	first, second := &MemoryWriter{}, &MemoryWriter{}
+	w := NewMultiWriter(first, second)
+
+	w.Write("c:counter:1")
+	w.WriteString("c:counter:2")
+	w.WriteBytes([]byte("c:counter:3"))
+
+	for _, mw := range []*MemoryWriter{first, second} {
+		lines := mw.Lines()
+		if len(lines) != 3 || lines[0] != "c:counter:1" || lines[2] != "c:counter:3" {
+			t.Errorf("Expected each writer to receive the lines, got %v", lines)
+		}
+	}
+
+	if len(w.Writers()) != 2 {
+		t.Errorf("Expected 2 writers, got %d", len(w.Writers()))
+	}
+}
+
+func TestMultiWriter_CloseIsolatesErrors(t *testing.T) {
+	failing, mw := &failingWriter{}, &MemoryWriter{}
+	fw, err := NewFileWriter(filepath.Join(t.TempDir(), "metrics.log"), logger.NewDefaultLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewMultiWriter(failing, fw, mw)
+
+	w.Write("c:counter:1")
+	if err := w.Flush(); err == nil || !strings.Contains(err.Error(), "writer 0: flush failed") {
+		t.Errorf("Expected the flush error of the first writer, got %v", err)
+	}
+
+	err = w.Close()
+	if err == nil || !strings.Contains(err.Error(), "writer 0: close failed") {
+		t.Errorf("Expected the close error of the first writer, got %v", err)
+	}
+	if !failing.closed {
+		t.Error("Expected the failing writer to be closed")
+	}
+	// the file writer was closed despite the error of the first writer
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if !fw.closed {
+		t.Error("Expected the file writer to be closed")
+	}
+}
+
+func TestMultiWriter_Stats(t *testing.T) {
+	first, second := &MemoryWriter{}, &MemoryWriter{}
+	w := NewMultiWriter(first, second, &NoopWriter{})
+
+	w.Write("c:counter:1")
+	w.Write("c:counter:2")
+
+	stats := w.Stats()
+	if stats.LinesWritten != 2 {
+		t.Errorf("Expected 2 lines written, got %d", stats.LinesWritten)
+	}
+	if stats.BytesWritten != 44 {
+		t.Errorf("Expected the bytes of both writers, got %d", stats.BytesWritten)
+	}
+}
+
+func TestNewWriter_MultiLocation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.log")
+	w, err := NewWriter("multi:memory,file://"+path, logger.NewDefaultLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.Write("c:counter:1")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	writers := w.(*MultiWriter).Writers()
+	if lines := writers[0].(*MemoryWriter).Lines(); len(lines) != 1 || lines[0] != "c:counter:1" {
+		t.Errorf("Expected the line in memory, got %v", lines)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "c:counter:1\n" {
+		t.Errorf("Expected the line in the file, got %q", content)
+	}
+}
+
+func TestParseMultiLocation(t *testing.T) {
+	locations, err := ParseMultiLocation("multi:udp,file:///tmp/metrics.log?maxSize=10MB&maxFiles=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locations) != 2 || locations[0] != "udp" || locations[1] != "file:///tmp/metrics.log?maxSize=10MB&maxFiles=5" {
+		t.Errorf("Unexpected locations %v", locations)
+	}
+
+	if _, err := ParseMultiLocation("udp"); err == nil {
+		t.Error("Expected an error for a location without the multi prefix")
+	}
+}
@@ -0,0 +1,90 @@
+package writer
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a point-in-time snapshot of writer health and delivery statistics. All counts are
+// cumulative, since the writer was created.
+type Stats struct {
+	// LinesWritten is the number of protocol lines accepted from meters.
+	LinesWritten uint64
+	// BytesWritten is the number of bytes successfully delivered to the output location.
+	BytesWritten uint64
+	// WriteErrors is the number of failed writes to the output location.
+	WriteErrors uint64
+	// Drops is the number of lines or payloads which were discarded, and will never be delivered.
+	Drops uint64
+	// Overflows is the number of times a buffer ran out of space. For the LineBuffer, this triggers
+	// an early flush. For the LowLatencyBuffer, the line is dropped.
+	Overflows uint64
+	// Flushes is the number of buffer flushes.
+	Flushes uint64
+	// Reconnects is the number of times the writer re-established its connection.
+	Reconnects uint64
+	// LastErrorTime is the time of the most recent write error, or the zero value, if none occurred.
+	LastErrorTime time.Time
+}
+
+// StatsWriter is implemented by writers which track delivery statistics. It is optional, so callers
+// should check for it with a type assertion.
+type StatsWriter interface {
+	Stats() Stats
+}
+
+// writerStats holds the counters used to produce Stats. It is safe for concurrent use, and the zero
+// value is ready to use.
+type writerStats struct {
+	linesWritten  atomic.Uint64
+	bytesWritten  atomic.Uint64
+	writeErrors   atomic.Uint64
+	drops         atomic.Uint64
+	overflows     atomic.Uint64
+	flushes       atomic.Uint64
+	reconnects    atomic.Uint64
+	lastErrorTime atomic.Int64 // unix nanoseconds
+}
+
+func (s *writerStats) recordLine() {
+	s.linesWritten.Add(1)
+}
+
+func (s *writerStats) recordBytes(n int) {
+	s.bytesWritten.Add(uint64(n))
+}
+
+func (s *writerStats) recordError() {
+	s.writeErrors.Add(1)
+	s.lastErrorTime.Store(time.Now().UnixNano())
+}
+
+func (s *writerStats) snapshot() Stats {
+	stats := Stats{
+		LinesWritten: s.linesWritten.Load(),
+		BytesWritten: s.bytesWritten.Load(),
+		WriteErrors:  s.writeErrors.Load(),
+		Drops:        s.drops.Load(),
+		Overflows:    s.overflows.Load(),
+		Flushes:      s.flushes.Load(),
+		Reconnects:   s.reconnects.Load(),
+	}
+	if nanos := s.lastErrorTime.Load(); nanos != 0 {
+		stats.LastErrorTime = time.Unix(0, nanos)
+	}
+	return stats
+}
+
+// bufferStats adds the statistics tracked by the buffers, if any, to the snapshot of a writer.
+func bufferStats(stats Stats, lineBuffer *LineBuffer, lowLatencyBuffer *LowLatencyBuffer) Stats {
+	if lineBuffer != nil {
+		stats.Overflows += lineBuffer.stats.overflows.Load()
+		stats.Flushes += lineBuffer.stats.flushes.Load()
+	}
+	if lowLatencyBuffer != nil {
+		stats.Drops += lowLatencyBuffer.stats.drops.Load()
+		stats.Overflows += lowLatencyBuffer.stats.overflows.Load()
+		stats.Flushes += lowLatencyBuffer.stats.flushes.Load()
+	}
+	return stats
+}
@@ -0,0 +1,79 @@
+package writer
+
+import (
+	"github.com/Netflix/spectator-go/v2/spectator/logger"
+	"testing"
+	"time"
+)
+
+func TestStats_MemoryWriter(t *testing.T) {
+	mw := &MemoryWriter{}
+	mw.Write("c:counter:1")
+	mw.WriteString("status")
+
+	stats := mw.Stats()
+	if stats.LinesWritten != 1 {
+		t.Errorf("Expected 1 line written, got %d", stats.LinesWritten)
+	}
+	if stats.BytesWritten != 17 {
+		t.Errorf("Expected 17 bytes written, got %d", stats.BytesWritten)
+	}
+	if !stats.LastErrorTime.IsZero() {
+		t.Errorf("Expected zero LastErrorTime, got %v", stats.LastErrorTime)
+	}
+}
+
+func TestStats_RecordError(t *testing.T) {
+	var s writerStats
+	before := time.Now()
+	s.recordError()
+
+	stats := s.snapshot()
+	if stats.WriteErrors != 1 {
+		t.Errorf("Expected 1 write error, got %d", stats.WriteErrors)
+	}
+	if stats.LastErrorTime.Before(before) {
+		t.Errorf("Expected LastErrorTime after %v, got %v", before, stats.LastErrorTime)
+	}
+}
+
+func TestStats_UdpWriterLineBuffer(t *testing.T) {
+	writer, err := NewUdpWriterWithBuffer("localhost:5000", logger.NewDefaultLogger(), 20, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Could not create UDP writer: %v", err)
+	}
+
+	writer.Write("message1")
+	writer.Write("message2")
+	writer.Write("message3")
+	_ = writer.Close()
+
+	stats := writer.Stats()
+	if stats.LinesWritten != 3 {
+		t.Errorf("Expected 3 lines written, got %d", stats.LinesWritten)
+	}
+	if stats.Overflows != 1 {
+		t.Errorf("Expected 1 overflow, got %d", stats.Overflows)
+	}
+	if stats.Flushes != 1 {
+		t.Errorf("Expected 1 flush, got %d", stats.Flushes)
+	}
+}
+
+func TestStats_LowLatencyBufferDrops(t *testing.T) {
+	memWriter := &MemoryWriter{}
+	buffer := NewLowLatencyBuffer(memWriter, logger.NewDefaultLogger(), 0, 3*time.Minute)
+	defer buffer.Close()
+
+	// Each shard holds a single chunk, so a line larger than a chunk is always dropped
+	buffer.Write(string(make([]byte, chunkSize+1)))
+	buffer.swapAndFlush()
+
+	stats := bufferStats(Stats{}, nil, buffer)
+	if stats.Drops != 1 || stats.Overflows != 1 {
+		t.Errorf("Expected 1 drop and 1 overflow, got %d drops and %d overflows", stats.Drops, stats.Overflows)
+	}
+	if stats.Flushes != 1 {
+		t.Errorf("Expected 1 flush, got %d", stats.Flushes)
+	}
+}
@@ -6,9 +6,12 @@ import (
 )
 
 // StderrWriter is a writer that writes to stderr.
-type StderrWriter struct{}
+type StderrWriter struct {
+	stats writerStats
+}
 
 func (s *StderrWriter) Write(line string) {
+	s.stats.recordLine()
 	s.WriteString(line)
 }
 
@@ -17,7 +20,17 @@ func (s *StderrWriter) WriteBytes(line []byte) {
 }
 
 func (s *StderrWriter) WriteString(line string) {
-	_, _ = fmt.Fprintln(os.Stderr, line)
+	n, err := fmt.Fprintln(os.Stderr, line)
+	if err != nil {
+		s.stats.recordError()
+		return
+	}
+	s.stats.recordBytes(n)
+}
+
+// Stats returns the delivery statistics of the writer.
+func (s *StderrWriter) Stats() Stats {
+	return s.stats.snapshot()
 }
 
 func (s *StderrWriter) Close() error {
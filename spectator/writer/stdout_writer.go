@@ -6,9 +6,12 @@ import (
 )
 
 // StdoutWriter is a writer that writes to stdout.
-type StdoutWriter struct{}
+type StdoutWriter struct {
+	stats writerStats
+}
 
 func (s *StdoutWriter) Write(line string) {
+	s.stats.recordLine()
 	s.WriteString(line)
 }
 
@@ -17,7 +20,17 @@ func (s *StdoutWriter) WriteBytes(line []byte) {
 }
 
 func (s *StdoutWriter) WriteString(line string) {
-	_, _ = fmt.Fprintln(os.Stdout, line)
+	n, err := fmt.Fprintln(os.Stdout, line)
+	if err != nil {
+		s.stats.recordError()
+		return
+	}
+	s.stats.recordBytes(n)
+}
+
+// Stats returns the delivery statistics of the writer.
+func (s *StdoutWriter) Stats() Stats {
+	return s.stats.snapshot()
 }
 
 func (s *StdoutWriter) Close() error {
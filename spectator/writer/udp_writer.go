@@ -2,15 +2,26 @@ package writer
 
 import (
 	"github.com/Netflix/spectator-go/v2/spectator/logger"
+	"log/slog"
 	"net"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type UdpWriter struct {
+	// location is the output location, which is logged as the `location` attribute.
+	location         string
 	conn             *net.UDPConn
 	logger           logger.Logger
 	lineBuffer       *LineBuffer
 	lowLatencyBuffer *LowLatencyBuffer
+	stats            writerStats
+
+	// closed discards the lines written after Close, instead of writing to the closed connection.
+	closed    atomic.Bool
+	closeOnce sync.Once
+	closeErr  error
 }
 
 type udpBufferWriter struct {
@@ -33,8 +44,9 @@ func NewUdpWriterWithBuffer(address string, logger logger.Logger, bufferSize int
 	}
 
 	baseWriter := &UdpWriter{
-		conn:   conn,
-		logger: logger,
+		location: "udp://" + address,
+		conn:     conn,
+		logger:   logger,
 	}
 
 	var lineBuffer *LineBuffer
@@ -51,7 +63,11 @@ func NewUdpWriterWithBuffer(address string, logger logger.Logger, bufferSize int
 }
 
 func (u *UdpWriter) Write(line string) {
-	u.logger.Debugf("Sending line: %s", line)
+	if logger.Enabled(u.logger, logger.LevelDebug) {
+		logger.LogAttrs(u.logger, logger.LevelDebug, "Sending line",
+			slog.String("location", u.location), slog.String("line", line))
+	}
+	u.stats.recordLine()
 
 	if u.lineBuffer != nil {
 		u.lineBuffer.Write(line)
@@ -67,34 +83,59 @@ func (u *UdpWriter) Write(line string) {
 }
 
 func (u *UdpWriter) WriteBytes(line []byte) {
-	_, err := u.conn.Write(line)
+	if u.closed.Load() {
+		u.stats.drops.Add(1)
+		return
+	}
+
+	n, err := u.conn.Write(line)
 	if err != nil {
-		u.logger.Errorf("Error writing to UDP: %s", err)
+		u.stats.recordError()
+		logger.LogAttrs(u.logger, logger.LevelError, "Error writing to UDP",
+			slog.String("location", u.location), slog.Any("error", err))
+		return
 	}
+	u.stats.recordBytes(n)
 }
 
 func (u *UdpWriter) WriteString(line string) {
-	_, err := u.conn.Write([]byte(line))
-	if err != nil {
-		u.logger.Errorf("Error writing to UDP: %s", err)
-	}
+	u.WriteBytes([]byte(line))
 }
 
-func (u *UdpWriter) Close() error {
-	// Stop flush timer, and flush remaining lines
+// Stats returns the delivery statistics of the writer, including those of its buffer.
+func (u *UdpWriter) Stats() Stats {
+	return bufferStats(u.stats.snapshot(), u.lineBuffer, u.lowLatencyBuffer)
+}
+
+// Flush delivers the lines held by the buffer, if any.
+func (u *UdpWriter) Flush() error {
 	if u.lineBuffer != nil {
-		u.lineBuffer.Close()
+		u.lineBuffer.Flush()
 	}
-
-	// Stop flush goroutines
 	if u.lowLatencyBuffer != nil {
-		u.lowLatencyBuffer.Close()
-	}
-
-	// Close the connection, if it exists
-	if u.conn != nil {
-		return u.conn.Close()
+		u.lowLatencyBuffer.Flush()
 	}
-
 	return nil
 }
+
+// Close flushes the buffer, and closes the connection. It is safe to call more than once.
+func (u *UdpWriter) Close() error {
+	u.closeOnce.Do(func() {
+		// Stop flush timer, and flush remaining lines
+		if u.lineBuffer != nil {
+			u.lineBuffer.Close()
+		}
+
+		// Stop flush goroutines
+		if u.lowLatencyBuffer != nil {
+			u.lowLatencyBuffer.Close()
+		}
+
+		// Close the connection, if it exists
+		u.closed.Store(true)
+		if u.conn != nil {
+			u.closeErr = u.conn.Close()
+		}
+	})
+	return u.closeErr
+}
@@ -3,6 +3,7 @@ package writer
 import (
 	"github.com/Netflix/spectator-go/v2/spectator/logger"
 	"net"
+	"runtime"
 	"sort"
 	"strconv"
 	"sync"
@@ -294,3 +295,22 @@ func TestConcurrentWrites(t *testing.T) {
 		}
 	}
 }
+
+func TestUdpWriter_CloseTwiceWithLowLatencyBuffer(t *testing.T) {
+	writer, err := NewUdpWriterWithBuffer("localhost:5000", logger.NewDefaultLogger(), 2*chunkSize*runtime.NumCPU(), time.Hour)
+	if err != nil {
+		t.Fatalf("Could not create UDP writer: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Errorf("Unexpected error on second close: %v", err)
+	}
+
+	writer.WriteString("c:after.close:1")
+	if stats := writer.Stats(); stats.Drops != 1 || stats.WriteErrors != 0 {
+		t.Errorf("Expected the write after close to be dropped without error, got %+v", stats)
+	}
+}
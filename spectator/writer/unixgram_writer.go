@@ -1,18 +1,108 @@
 package writer
 
 import (
+	"errors"
+	"fmt"
 	"github.com/Netflix/spectator-go/v2/spectator/logger"
+	"log/slog"
+	"math/rand"
 	"net"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 )
 
+// retryQueueSize is the maximum number of payloads held while the unix socket is reconnecting. When
+// the queue is full, the oldest payload is dropped to make room for the newest one.
+const retryQueueSize = 64
+
+// minRedialBackoff and maxRedialBackoff bound the delay between attempts to re-dial the unix socket.
+// The delay doubles after each failed attempt, and a random jitter is applied, so that many processes
+// on the same host do not hammer a restarting spectatord at the same time.
+const (
+	minRedialBackoff = 10 * time.Millisecond
+	maxRedialBackoff = 5 * time.Second
+)
+
+// unixLocationPrefix starts a unix domain socket location, such as `unix:///run/spectatord/spectatord.unix`,
+// or `unix://@spectatord` for a Linux abstract socket.
+const unixLocationPrefix = "unix://"
+
+// maxUnixPathLength returns the size of sun_path in sockaddr_un, which bounds the length of the socket
+// path: 108 bytes on Linux, and 104 bytes on macOS and the BSDs.
+func maxUnixPathLength() int {
+	if runtime.GOOS == "linux" {
+		return 108
+	}
+	return 104
+}
+
+// ParseUnixLocation returns the socket path of a `unix://` output location, after checking that it fits
+// within sun_path. A path which starts with `@` names a Linux abstract socket, such as `unix://@spectatord`,
+// which has no file in the filesystem.
+func ParseUnixLocation(location string) (string, error) {
+	if !strings.HasPrefix(location, unixLocationPrefix) {
+		return "", fmt.Errorf("invalid unix location %s: missing %s prefix", location, unixLocationPrefix)
+	}
+	path := strings.TrimPrefix(location, unixLocationPrefix)
+	if err := validateUnixPath(path); err != nil {
+		return "", fmt.Errorf("invalid unix location %s: %w", location, err)
+	}
+	return path, nil
+}
+
+// validateUnixPath checks that the socket path fits within sun_path. A filesystem path must leave room
+// for its NUL terminator, while the name of an abstract socket is not terminated, and the leading `@`
+// stands for the NUL byte which starts it, so it may use all of sun_path.
+func validateUnixPath(path string) error {
+	maxLength := maxUnixPathLength()
+	switch {
+	case path == "":
+		return fmt.Errorf("empty socket path")
+	case strings.HasPrefix(path, "@"):
+		if runtime.GOOS != "linux" {
+			return fmt.Errorf("abstract socket %s is only supported on Linux", path)
+		}
+		if len(path) == 1 {
+			return fmt.Errorf("empty abstract socket name")
+		}
+		if len(path) > maxLength {
+			return fmt.Errorf("abstract socket %s is %d bytes, longer than the %d bytes of sun_path", path, len(path), maxLength)
+		}
+	case len(path) >= maxLength:
+		return fmt.Errorf("socket path %s is %d bytes, longer than the %d bytes of sun_path, including the NUL terminator", path, len(path), maxLength-1)
+	}
+	return nil
+}
+
+func isValidUnixLocation(output string) bool {
+	_, err := ParseUnixLocation(output)
+	return err == nil
+}
+
 type UnixgramWriter struct {
+	// location is the output location, which is logged as the `location` attribute.
+	location         string
 	addr             *net.UnixAddr
-	conn             *net.UnixConn
 	logger           logger.Logger
 	lineBuffer       *LineBuffer
 	lowLatencyBuffer *LowLatencyBuffer
+
+	// mu guards the connection and the reconnect state, which are shared by concurrent writers.
+	mu         sync.Mutex
+	conn       *net.UnixConn
+	backoff    time.Duration
+	nextRedial time.Time
+	retryQueue [][]byte
+	closed     bool
+
+	// drops counts payloads that could not be delivered since the last report. It is published as a
+	// status metric and reset, after the socket is reconnected.
+	drops atomic.Int64
+	stats writerStats
 }
 
 type unixgramBufferWriter struct {
@@ -20,21 +110,31 @@ type unixgramBufferWriter struct {
 }
 
 func NewUnixgramWriter(path string, logger logger.Logger) (*UnixgramWriter, error) {
-	return NewUnixgramWriterWithBuffer(path, logger, 0, 5 * time.Second)
+	return NewUnixgramWriterWithBuffer(path, logger, 0, 5*time.Second)
 }
 
+// NewUnixgramWriterWithBuffer creates a UnixgramWriter for the socket path, or for the Linux abstract
+// socket, if the path starts with `@`. An error is returned if the path does not fit within sun_path, but
+// not if the socket is unavailable, because the writer re-dials it.
 func NewUnixgramWriterWithBuffer(path string, logger logger.Logger, bufferSize int, flushInterval time.Duration) (*UnixgramWriter, error) {
-	addr := &net.UnixAddr{Name: path, Net: "unixgram"}
-	conn, err := net.DialUnix("unixgram", nil, addr)
-	if err != nil {
-		logger.Errorf("failed to dial unix socket: %v", err)
-		conn = nil
+	if err := validateUnixPath(path); err != nil {
+		return nil, fmt.Errorf("invalid unix socket: %w", err)
 	}
 
+	addr := &net.UnixAddr{Name: path, Net: "unixgram"}
+
 	baseWriter := &UnixgramWriter{
-		addr:   addr,
-		conn:   conn,
-		logger: logger,
+		location: unixLocationPrefix + path,
+		addr:     addr,
+		logger:   logger,
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, addr)
+	if err != nil {
+		baseWriter.logError("failed to dial unix socket", err)
+		baseWriter.scheduleRedialLocked()
+	} else {
+		baseWriter.conn = conn
 	}
 
 	var lineBuffer *LineBuffer
@@ -51,7 +151,11 @@ func NewUnixgramWriterWithBuffer(path string, logger logger.Logger, bufferSize i
 }
 
 func (u *UnixgramWriter) Write(line string) {
-	u.logger.Debugf("Sending line: %s", line)
+	if logger.Enabled(u.logger, logger.LevelDebug) {
+		logger.LogAttrs(u.logger, logger.LevelDebug, "Sending line",
+			slog.String("location", u.location), slog.String("line", line))
+	}
+	u.stats.recordLine()
 
 	if u.lineBuffer != nil {
 		u.lineBuffer.Write(line)
@@ -67,28 +171,110 @@ func (u *UnixgramWriter) Write(line string) {
 }
 
 func (u *UnixgramWriter) WriteBytes(line []byte) {
-	if u.conn != nil {
-		if _, err := u.conn.Write(line); err != nil {
-			u.maybeCloseSocket(err)
-		}
-	} else {
-		u.redialSocket()
-	}
+	// The buffers reuse their chunks after a flush, so take a copy, in case the line must be queued.
+	u.send(append([]byte(nil), line...))
 }
 
 func (u *UnixgramWriter) WriteString(line string) {
-	if u.conn != nil {
-		if _, err := u.conn.Write([]byte(line)); err != nil {
-			u.maybeCloseSocket(err)
+	u.send([]byte(line))
+}
+
+// send writes the payload to the unix socket, if it is connected. If it is not connected, then the
+// payload is queued, and a re-dial is attempted once the backoff delay has elapsed. Queued payloads
+// are delivered first when the connection is restored, followed by a status metric which reports the
+// reconnect. The drops which are not reported yet, such as the payloads dropped while the socket was
+// unavailable, or because its buffer was full, are reported after the next successful write.
+func (u *UnixgramWriter) send(payload []byte) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	// do not re-dial the socket after Close, and discard the payload instead
+	if u.closed {
+		u.stats.drops.Add(1)
+		return
+	}
+
+	reconnected := false
+	if u.conn == nil {
+		if !u.redialLocked() {
+			u.enqueueLocked(payload)
+			return
 		}
-	} else {
-		u.redialSocket()
+		reconnected = true
+	}
+
+	for len(u.retryQueue) > 0 && u.conn != nil {
+		queued := u.retryQueue[0]
+		u.retryQueue = u.retryQueue[1:]
+		u.writeLocked(queued)
+	}
+
+	if u.conn == nil {
+		// the socket was closed while draining the retry queue
+		u.enqueueLocked(payload)
+		return
+	}
+
+	if !u.writeLocked(payload) {
+		return
+	}
+
+	if reconnected {
+		u.writeLocked([]byte("c:spectator-go.unixgramWriter.reconnects:1"))
 	}
+	u.reportDropsLocked()
 }
 
+// reportDropsLocked writes the status metric for the drops which are not reported yet. If the status
+// metric is dropped as well, then the drops are kept, to be reported after the next successful write. The
+// caller must hold u.mu.
+func (u *UnixgramWriter) reportDropsLocked() {
+	drops := u.drops.Swap(0)
+	if drops == 0 {
+		return
+	}
+	// a status metric which fails with a reconnect error is queued, and delivered after the reconnect
+	if !u.writeLocked([]byte(fmt.Sprintf("c:spectator-go.unixgramWriter.drops:%d", drops))) && u.conn != nil {
+		u.drops.Add(drops)
+	}
+}
+
+// writeLocked writes a payload to the connected socket, and reports whether it was delivered. Errors
+// which indicate that spectatord is unavailable close the socket, and the payload is returned to the
+// front of the retry queue. Transient errors, such as a full socket buffer, drop the payload. The
+// caller must hold u.mu.
+func (u *UnixgramWriter) writeLocked(payload []byte) bool {
+	n, err := u.conn.Write(payload)
+	if err == nil {
+		u.stats.recordBytes(n)
+		return true
+	}
+	u.stats.recordError()
+
+	switch {
+	case isReconnectError(err):
+		u.logError("failed to write to unix socket, will reconnect", err)
+		u.closeLocked()
+		u.scheduleRedialLocked()
+		u.retryQueue = append([][]byte{payload}, u.retryQueue...)
+		u.trimQueueLocked()
+	case isTransientError(err):
+		logger.LogAttrs(u.logger, logger.LevelDebug, "dropped payload for unix socket, buffer is full",
+			slog.String("location", u.location), slog.Int("bytes", len(payload)), slog.Any("error", err))
+		u.recordDrops(1)
+	default:
+		u.logError("failed to write to unix socket", err)
+		u.recordDrops(1)
+	}
+
+	return false
+}
+
+// isReconnectError reports errors which indicate that the socket must be closed and re-dialed.
+//
 // If anything disturbs access to the unix socket, such as a spectatord process restart (or another
-// unknown condition), then all future writes to the unix socket will fail with a "transport endpoint
-// is not connected" error.
+// unknown condition), then all future writes to the unix socket will fail with ECONNREFUSED, ENOTCONN,
+// or ENOENT, depending upon whether the socket file still exists.
 //
 // This means that the UdpWriter is generally more resilient across more operating conditions than the
 // UnixgramWriter. The UdpWriter does not continue to fail once it encounters a single failure to write,
@@ -96,32 +282,116 @@ func (u *UnixgramWriter) WriteString(line string) {
 // handling.
 //
 // The addition of reconnect logic to the UnixgramWriter mitigates ongoing issues with unix socket write
-// errors. Some packet delivery failure will occur until it can reconnect. With the reconnect logic in
-// place, the initialization is now more resilient if the unix socket is not available at program start.
-func (u *UnixgramWriter) maybeCloseSocket(err error) {
-	u.logger.Errorf("failed to write to unix socket: %v\n", err)
-
-	if strings.Contains(err.Error(), "transport endpoint is not connected") {
-		u.logger.Infof("close unix socket")
-		err := u.conn.Close()
-		if err != nil {
-			u.logger.Errorf("failed to close unix socket: %v\n", err)
-		}
-		u.conn = nil
-	}
+// errors. Payloads written while the socket is unavailable are held in a small retry queue, and they are
+// delivered once the socket reconnects. With the reconnect logic in place, the initialization is now
+// more resilient if the unix socket is not available at program start.
+func isReconnectError(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ENOTCONN) ||
+		errors.Is(err, syscall.ENOENT) ||
+		errors.Is(err, net.ErrClosed)
 }
 
-func (u *UnixgramWriter) redialSocket() {
-	u.logger.Infof("re-dial unix socket")
+// isTransientError reports errors which indicate that the spectatord receive buffer is full. The
+// socket remains usable, so these do not trigger a reconnect.
+func isTransientError(err error) bool {
+	return errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.ENOBUFS)
+}
+
+// redialLocked attempts to re-dial the unix socket, if the backoff delay has elapsed, and reports
+// whether the socket is connected. The caller must hold u.mu.
+func (u *UnixgramWriter) redialLocked() bool {
+	if time.Now().Before(u.nextRedial) {
+		return false
+	}
+
+	logger.LogAttrs(u.logger, logger.LevelInfo, "re-dial unix socket", slog.String("location", u.location))
 
 	conn, err := net.DialUnix("unixgram", nil, u.addr)
 	if err != nil {
-		u.logger.Errorf("failed to dial unix socket: %v", err)
-	} else {
-		u.conn = conn
+		u.logError("failed to dial unix socket", err)
+		u.scheduleRedialLocked()
+		return false
+	}
+
+	u.conn = conn
+	u.backoff = 0
+	u.nextRedial = time.Time{}
+	u.stats.reconnects.Add(1)
+	return true
+}
+
+// scheduleRedialLocked doubles the backoff delay, up to the maximum, and sets the time of the next
+// re-dial attempt, with jitter applied to the second half of the delay. The caller must hold u.mu.
+func (u *UnixgramWriter) scheduleRedialLocked() {
+	u.backoff *= 2
+	if u.backoff < minRedialBackoff {
+		u.backoff = minRedialBackoff
+	}
+	if u.backoff > maxRedialBackoff {
+		u.backoff = maxRedialBackoff
+	}
+
+	half := u.backoff / 2
+	u.nextRedial = time.Now().Add(half + time.Duration(rand.Int63n(int64(half)+1)))
+}
+
+// enqueueLocked adds a payload to the end of the retry queue. The caller must hold u.mu.
+func (u *UnixgramWriter) enqueueLocked(payload []byte) {
+	u.retryQueue = append(u.retryQueue, payload)
+	u.trimQueueLocked()
+}
+
+// trimQueueLocked drops the oldest payloads, until the retry queue fits within its maximum size. The
+// caller must hold u.mu.
+func (u *UnixgramWriter) trimQueueLocked() {
+	if excess := len(u.retryQueue) - retryQueueSize; excess > 0 {
+		u.recordDrops(excess)
+		u.retryQueue = u.retryQueue[excess:]
+	}
+}
+
+// recordDrops counts dropped payloads, both for the next status metric report, and for Stats.
+func (u *UnixgramWriter) recordDrops(n int) {
+	u.drops.Add(int64(n))
+	u.stats.drops.Add(uint64(n))
+}
+
+// closeLocked closes the socket, if it is open. The caller must hold u.mu.
+func (u *UnixgramWriter) closeLocked() error {
+	if u.conn == nil {
+		return nil
+	}
+
+	logger.LogAttrs(u.logger, logger.LevelInfo, "close unix socket", slog.String("location", u.location))
+	err := u.conn.Close()
+	if err != nil {
+		u.logError("failed to close unix socket", err)
 	}
+	u.conn = nil
+	return err
 }
 
+// logError logs an error of the unix socket, with the location.
+func (u *UnixgramWriter) logError(msg string, err error) {
+	logger.LogAttrs(u.logger, logger.LevelError, msg, slog.String("location", u.location), slog.Any("error", err))
+}
+
+// Stats returns the delivery statistics of the writer, including those of its buffer.
+func (u *UnixgramWriter) Stats() Stats {
+	return bufferStats(u.stats.snapshot(), u.lineBuffer, u.lowLatencyBuffer)
+}
+
+// Flush delivers the lines held by the buffer, if any.
+func (u *UnixgramWriter) Flush() error {
+	if u.lineBuffer != nil {
+		u.lineBuffer.Flush()
+	}
+	if u.lowLatencyBuffer != nil {
+		u.lowLatencyBuffer.Flush()
+	}
+	return nil
+}
 
 func (u *UnixgramWriter) Close() error {
 	// Stop flush timer, and flush remaining lines
@@ -134,10 +404,12 @@ func (u *UnixgramWriter) Close() error {
 		u.lowLatencyBuffer.Close()
 	}
 
-	// Close the connection
-	if u.conn != nil {
-		return u.conn.Close()
-	}
-
-	return nil
+	// Close the connection, and discard the lines written afterwards
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.closed = true
+	// the queued payloads are never delivered, so count them as drops
+	u.recordDrops(len(u.retryQueue))
+	u.retryQueue = nil
+	return u.closeLocked()
 }
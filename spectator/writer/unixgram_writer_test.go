@@ -2,10 +2,14 @@ package writer
 
 import (
 	"errors"
+	"fmt"
 	"github.com/Netflix/spectator-go/v2/spectator/logger"
 	"log"
 	"net"
 	"os"
+	"runtime"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -211,3 +215,241 @@ func TestUnixgramWriter_LineBuffer_Write(t *testing.T) {
 	// Allow time for messages to deliver
 	time.Sleep(2 * time.Millisecond)
 }
+
+func TestUnixgramWriter_ReconnectAfterServerRestart(t *testing.T) {
+	// Create server
+	server, msgCh, serverErr := newUnixgramServer()
+	if serverErr != nil {
+		t.Fatalf("Failed to create unixgram server: %v", serverErr)
+	}
+
+	// Create writer
+	writer, err := NewUnixgramWriter(testUnixgramSocket, logger.NewDefaultLogger())
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer writer.Close()
+
+	writer.Write("message1")
+	recvMsg, recvErr := readMessage(msgCh)
+	if recvErr != nil || recvMsg != "message1" {
+		t.Fatalf("Expected 'message1', got '%s' (%v)", recvMsg, recvErr)
+	}
+
+	// Stop the server, so that the next write fails and is queued for retry
+	_ = server.Close()
+	writer.Write("message2")
+	if writer.conn != nil {
+		t.Errorf("Expected connection to be closed after write failure")
+	}
+
+	// Restart the server, and wait for the redial backoff to elapse
+	server, msgCh, serverErr = newUnixgramServer()
+	if serverErr != nil {
+		t.Fatalf("Failed to create unixgram server: %v", serverErr)
+	}
+	defer server.Close()
+	time.Sleep(2 * minRedialBackoff)
+
+	writer.Write("message3")
+
+	expected := []string{"message2", "message3", "c:spectator-go.unixgramWriter.reconnects:1"}
+	for _, exp := range expected {
+		recvMsg, recvErr = readMessage(msgCh)
+		if recvErr != nil {
+			t.Errorf("Failed to receive message: %v", recvErr)
+		}
+		if recvMsg != exp {
+			t.Errorf("Received message '%s' does not match expected message '%s'", recvMsg, exp)
+		}
+	}
+
+	if writer.Stats().Reconnects != 1 {
+		t.Errorf("Expected 1 reconnect, got %d", writer.Stats().Reconnects)
+	}
+}
+
+func TestUnixgramWriter_RetryQueueDropsOldest(t *testing.T) {
+	_ = os.RemoveAll(testUnixgramSocket)
+
+	writer, err := NewUnixgramWriter(testUnixgramSocket, logger.NewDefaultLogger())
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer writer.Close()
+
+	for i := 0; i < retryQueueSize+5; i++ {
+		writer.Write(fmt.Sprintf("message%d", i))
+	}
+
+	if len(writer.retryQueue) != retryQueueSize {
+		t.Errorf("Expected %d queued payloads, got %d", retryQueueSize, len(writer.retryQueue))
+	}
+	if string(writer.retryQueue[0]) != "message5" {
+		t.Errorf("Expected oldest queued payload 'message5', got '%s'", writer.retryQueue[0])
+	}
+	if writer.drops.Load() != 5 {
+		t.Errorf("Expected 5 drops, got %d", writer.drops.Load())
+	}
+}
+
+func TestUnixgramWriter_CloseCountsQueuedPayloads(t *testing.T) {
+	_ = os.RemoveAll(testUnixgramSocket)
+
+	writer, err := NewUnixgramWriter(testUnixgramSocket, logger.NewDefaultLogger())
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		writer.Write(fmt.Sprintf("message%d", i))
+	}
+	_ = writer.Close()
+
+	if drops := writer.Stats().Drops; drops != 3 {
+		t.Errorf("Expected the 3 queued payloads to be counted as drops, got %d", drops)
+	}
+	if drops := writer.drops.Load(); drops != 3 {
+		t.Errorf("Expected 3 drops for the status metric, got %d", drops)
+	}
+}
+
+func TestUnixgramWriter_ErrorClassification(t *testing.T) {
+	testCases := []struct {
+		err       error
+		reconnect bool
+		transient bool
+	}{
+		{&net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.ECONNREFUSED)}, true, false},
+		{&net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.ENOTCONN)}, true, false},
+		{&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ENOENT)}, true, false},
+		{&net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.EAGAIN)}, false, true},
+		{&net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.ENOBUFS)}, false, true},
+		{errors.New("unexpected"), false, false},
+	}
+
+	for _, tc := range testCases {
+		if isReconnectError(tc.err) != tc.reconnect {
+			t.Errorf("Expected isReconnectError=%v for '%v'", tc.reconnect, tc.err)
+		}
+		if isTransientError(tc.err) != tc.transient {
+			t.Errorf("Expected isTransientError=%v for '%v'", tc.transient, tc.err)
+		}
+	}
+}
+
+func TestUnixgramWriter_AbstractSocket(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("abstract unix sockets are only supported on Linux")
+	}
+
+	// Create server, on an abstract socket which has no file to clean up
+	name := fmt.Sprintf("@spectator-go-test-%d", os.Getpid())
+	server, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Failed to create abstract unixgram server: %v", err)
+	}
+	defer server.Close()
+	msgCh := make(chan string)
+	go handleConnections(server, msgCh)
+
+	location := "unix://" + name
+	if !IsValidOutputLocation(location) {
+		t.Errorf("Expected %s to be a valid output location", location)
+	}
+	w, err := NewWriter(location, logger.NewDefaultLogger())
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer w.Close()
+	if _, ok := w.(*UnixgramWriter); !ok {
+		t.Fatalf("Expected a UnixgramWriter, got %T", w)
+	}
+
+	w.Write("c:abstract:1")
+	msg, err := readMessage(msgCh)
+	if err != nil {
+		t.Fatalf("Failed to receive message: %v", err)
+	}
+	if msg != "c:abstract:1" {
+		t.Errorf("Expected 'c:abstract:1', got '%s'", msg)
+	}
+}
+
+func TestParseUnixLocation(t *testing.T) {
+	maxLength := maxUnixPathLength()
+	longest := "/" + strings.Repeat("a", maxLength-2)
+	// abstract sockets are only valid on Linux, and their name may use all of sun_path
+	linux := runtime.GOOS == "linux"
+	abstract := "@" + strings.Repeat("a", maxLength-1)
+
+	testCases := []struct {
+		location string
+		valid    bool
+	}{
+		{"unix:///run/spectatord/spectatord.unix", true},
+		{"unix://" + longest, true},
+		{"unix://" + longest + "a", false},
+		{"unix://", false},
+		{"unix://@", false},
+		{"unix://@spectatord", linux},
+		{"unix://" + abstract, linux},
+		{"unix://" + abstract + "a", false},
+	}
+
+	for _, tc := range testCases {
+		path, err := ParseUnixLocation(tc.location)
+		if (err == nil) != tc.valid {
+			t.Errorf("Expected valid=%v for '%s', got error %v", tc.valid, tc.location, err)
+		}
+		if tc.valid && path != strings.TrimPrefix(tc.location, "unix://") {
+			t.Errorf("Unexpected path '%s' for '%s'", path, tc.location)
+		}
+		if IsValidOutputLocation(tc.location) != tc.valid {
+			t.Errorf("Expected IsValidOutputLocation=%v for '%s'", tc.valid, tc.location)
+		}
+	}
+
+	if _, err := ParseUnixLocation("udp://127.0.0.1:1234"); err == nil {
+		t.Errorf("Expected an error for a location without the unix:// prefix")
+	}
+}
+
+func TestNewUnixgramWriter_PathTooLong(t *testing.T) {
+	path := "/tmp/" + strings.Repeat("a", maxUnixPathLength())
+	if _, err := NewUnixgramWriter(path, logger.NewDefaultLogger()); err == nil {
+		t.Errorf("Expected an error for a path longer than sun_path")
+	}
+}
+
+func TestUnixgramWriter_ReportsDropsWithoutReconnect(t *testing.T) {
+	server, msgCh, serverErr := newUnixgramServer()
+	if serverErr != nil {
+		t.Fatalf("Failed to create unixgram server: %v", serverErr)
+	}
+	defer server.Close()
+
+	writer, err := NewUnixgramWriter(testUnixgramSocket, logger.NewDefaultLogger())
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer writer.Close()
+
+	// a transient error, such as a full socket buffer, drops the payload without closing the socket
+	writer.recordDrops(2)
+	writer.Write("message1")
+
+	expected := []string{"message1", "c:spectator-go.unixgramWriter.drops:2"}
+	for _, exp := range expected {
+		recvMsg, recvErr := readMessage(msgCh)
+		if recvErr != nil || recvMsg != exp {
+			t.Errorf("Expected '%s', got '%s' (%v)", exp, recvMsg, recvErr)
+		}
+	}
+	if writer.drops.Load() != 0 {
+		t.Errorf("Expected the drops to be reported, got %d pending", writer.drops.Load())
+	}
+	if writer.Stats().Reconnects != 0 {
+		t.Errorf("Expected no reconnect, got %d", writer.Stats().Reconnects)
+	}
+}
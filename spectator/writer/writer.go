@@ -17,6 +17,12 @@ type Writer interface {
 	Close() error
 }
 
+// Flusher is implemented by writers which buffer lines, to deliver the buffered lines immediately. It is
+// optional, so callers should check for it with a type assertion.
+type Flusher interface {
+	Flush() error
+}
+
 func IsValidOutputLocation(output string) bool {
 	return output == "none" ||
 		output == "memory" ||
@@ -24,9 +30,18 @@ func IsValidOutputLocation(output string) bool {
 		output == "stderr" ||
 		output == "udp" ||
 		output == "unix" ||
-		strings.HasPrefix(output, "file://") ||
+		isValidFileLocation(output) ||
 		strings.HasPrefix(output, "udp://") ||
-		strings.HasPrefix(output, "unix://")
+		isValidUnixLocation(output) ||
+		isValidMultiLocation(output)
+}
+
+func isValidFileLocation(output string) bool {
+	if !strings.HasPrefix(output, "file://") {
+		return false
+	}
+	_, _, err := ParseFileLocation(output)
+	return err == nil
 }
 
 // NewWriter Create a new writer based on the GetLocation string provided
@@ -63,16 +78,43 @@ func NewWriterWithBuffer(outputLocation string, logger logger.Logger, bufferSize
 		return NewUnixgramWriterWithBuffer(path, logger, bufferSize, flushInterval)
 	case strings.HasPrefix(outputLocation, "file://"):
 		logger.Infof("Initialize FileWriter with path %s", outputLocation)
-		filePath := strings.TrimPrefix(outputLocation, "file://")
-		return NewFileWriter(filePath, logger)
+		filePath, options, err := ParseFileLocation(outputLocation)
+		if err != nil {
+			return nil, err
+		}
+		// Buffering is only enabled by the bufferSize of the location, and not by the general buffer
+		// settings, which are sized for the sockets
+		if options.FlushInterval == 0 {
+			options.FlushInterval = flushInterval
+		}
+		return NewFileWriterWithOptions(filePath, options, logger)
 	case strings.HasPrefix(outputLocation, "udp://"):
 		logger.Infof("Initialize UdpWriter with address %s", outputLocation)
 		address := strings.TrimPrefix(outputLocation, "udp://")
 		return NewUdpWriterWithBuffer(address, logger, bufferSize, flushInterval)
-	case strings.HasPrefix(outputLocation, "unix://"):
+	case strings.HasPrefix(outputLocation, unixLocationPrefix):
 		logger.Infof("Initialize UnixgramWriter with path %s", outputLocation)
-		path := strings.TrimPrefix(outputLocation, "unix://")
+		path, err := ParseUnixLocation(outputLocation)
+		if err != nil {
+			return nil, err
+		}
 		return NewUnixgramWriterWithBuffer(path, logger, bufferSize, flushInterval)
+	case strings.HasPrefix(outputLocation, multiLocationPrefix):
+		logger.Infof("Initialize MultiWriter with locations %s", outputLocation)
+		locations, err := ParseMultiLocation(outputLocation)
+		if err != nil {
+			return nil, err
+		}
+		writers := make([]Writer, 0, len(locations))
+		for _, location := range locations {
+			w, err := NewWriterWithBuffer(location, logger, bufferSize, flushInterval)
+			if err != nil {
+				_ = NewMultiWriter(writers...).Close()
+				return nil, err
+			}
+			writers = append(writers, w)
+		}
+		return NewMultiWriter(writers...), nil
 	default:
 		return nil, fmt.Errorf("unknown output location: %s", outputLocation)
 	}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"github.com/Netflix/spectator-go/v2/spectator/logger"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestValidOutputLocation(t *testing.T) {
@@ -20,8 +22,16 @@ func TestValidOutputLocation(t *testing.T) {
 		{"udp", true},
 		{"unix", true},
 		{"file://testfile.txt", true},
+		{"file://testfile.txt?maxSize=10MB&maxFiles=5&compress=true", true},
+		{"file://testfile.txt?maxSize=ten", false},
 		{"udp://localhost:1234", true},
 		{"unix:///tmp/socket.sock", true},
+		{"multi:udp,file:///tmp/metrics.log", true},
+		{"multi:memory", true},
+		{"multi:", false},
+		{"multi:udp,,memory", false},
+		{"multi:udp,invalid", false},
+		{"multi:multi:udp", false},
 		{"invalid", false},
 	}
 
@@ -45,6 +55,7 @@ func TestNewWriter(t *testing.T) {
 		{"file://testfile.txt", "*writer.FileWriter"},
 		{"udp://localhost:5000", "*writer.UdpWriter"},
 		{"unix:///tmp/socket.sock", "*writer.UnixgramWriter"},
+		{"multi:memory,stdout", "*writer.MultiWriter"},
 	}
 
 	for _, tc := range testCases {
@@ -59,6 +70,21 @@ func TestNewWriter(t *testing.T) {
 	}
 }
 
+func TestNewWriterWithBuffer_FileIgnoresBufferSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.log")
+	w, err := NewWriterWithBuffer("file://"+path, logger.NewDefaultLogger(), 4096, time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	w.Write("c:unbuffered:1")
+	content, _ := os.ReadFile(path)
+	if string(content) != "c:unbuffered:1\n" {
+		t.Errorf("Expected the line to be written without buffering, got '%s'", content)
+	}
+}
+
 func TestNewWriter_InvalidOutputLocation(t *testing.T) {
 	_, err := NewWriter("invalid", logger.NewDefaultLogger())
 	if err == nil {